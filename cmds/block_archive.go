@@ -0,0 +1,261 @@
+package cmds
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// blockArchiveManifestName is the manifest file name in block archive. The
+// manifest is written at the end of archive.
+var blockArchiveManifestName = "manifest.json"
+
+// zstdMagic is the magic number of zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// blockArchiveManifest describes the blocks in block archive; the files of
+// block are in the same layout with the local block data directory.
+type blockArchiveManifest struct {
+	CreatedAt time.Time           `json:"created_at"`
+	NetworkID string              `json:"network_id"`
+	Blocks    []blockArchiveBlock `json:"blocks"`
+	From      base.Height         `json:"from"`
+	To        base.Height         `json:"to"`
+}
+
+type blockArchiveBlock struct {
+	BlockMap json.RawMessage   `json:"blockmap"`
+	Files    map[string]string `json:"files"` // NOTE path and sha256 checksum
+	Height   base.Height       `json:"height"`
+}
+
+type blockArchiveWriter struct {
+	zw *zstd.Encoder
+	tw *tar.Writer
+}
+
+func newBlockArchiveWriter(w io.Writer) (*blockArchiveWriter, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &blockArchiveWriter{zw: zw, tw: tar.NewWriter(zw)}, nil
+}
+
+// add adds file to archive and returns the sha256 checksum.
+func (w *blockArchiveWriter) add(name string, size int64, r io.Reader) (string, error) {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     filepath.ToSlash(name),
+		Size:     size,
+		Mode:     0o600,
+		ModTime:  time.Unix(0, 0),
+	}); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	h := sha256.New()
+
+	if _, err := io.Copy(w.tw, io.TeeReader(r, h)); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (w *blockArchiveWriter) addBytes(name string, b []byte) (string, error) {
+	return w.add(name, int64(len(b)), bytes.NewReader(b))
+}
+
+func (w *blockArchiveWriter) close(manifest blockArchiveManifest) error {
+	b, err := util.MarshalJSONIndent(manifest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.addBytes(blockArchiveManifestName, b); err != nil {
+		return err
+	}
+
+	if err := w.tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(w.zw.Close())
+}
+
+// blockArchivePath returns the path of file in block archive; it is relative to
+// the block data directory.
+func blockArchivePath(elem ...string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Join(elem...)), "/")
+}
+
+// isBlockArchive checks whether the path is block archive file, not block data
+// directory. The file, which is not zstd compressed, is not block archive.
+func isBlockArchive(p string) (bool, error) {
+	switch fi, err := os.Stat(p); {
+	case err != nil:
+		return false, errors.WithStack(err)
+	case fi.IsDir():
+		return false, nil
+	case !fi.Mode().IsRegular():
+		return false, errors.Errorf("not block data directory or block archive, %q", p)
+	}
+
+	f, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	b := make([]byte, len(zstdMagic))
+
+	switch _, err := io.ReadFull(f, b); {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+	case err != nil:
+		return false, errors.WithStack(err)
+	case bytes.Equal(b, zstdMagic):
+		return true, nil
+	}
+
+	return false, errors.Errorf("not block archive; not zstd compressed, %q", p)
+}
+
+// extractBlockArchive extracts the block archive into directory and checks the
+// files by the checksums of manifest.
+func extractBlockArchive(archive, directory string) (*blockArchiveManifest, error) {
+	e := util.StringError("extract block archive")
+
+	f, err := os.Open(filepath.Clean(archive))
+	if err != nil {
+		return nil, e.Wrap(errors.WithStack(err))
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return nil, e.Wrap(errors.WithStack(err))
+	}
+
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+
+	sums := map[string]string{}
+
+	var manifest *blockArchiveManifest
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		switch {
+		case err != nil:
+			return nil, e.Wrap(errors.WithStack(err))
+		case hdr.Typeflag != tar.TypeReg:
+		case hdr.Name == blockArchiveManifestName:
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, e.Wrap(errors.WithStack(err))
+			}
+
+			var m blockArchiveManifest
+
+			if err := util.UnmarshalJSON(b, &m); err != nil {
+				return nil, e.WithMessage(err, "manifest")
+			}
+
+			manifest = &m
+		default:
+			sum, err := extractBlockArchiveFile(tr, directory, hdr.Name)
+			if err != nil {
+				return nil, e.Wrap(err)
+			}
+
+			sums[hdr.Name] = sum
+		}
+	}
+
+	if manifest == nil {
+		return nil, e.Errorf("manifest not found")
+	}
+
+	for i := range manifest.Blocks {
+		for name, sum := range manifest.Blocks[i].Files {
+			switch found, ok := sums[name]; {
+			case !ok:
+				return nil, e.Errorf("file not found in archive; height=%d file=%q", manifest.Blocks[i].Height, name)
+			case found != sum:
+				return nil, e.Errorf("checksum does not match; height=%d file=%q", manifest.Blocks[i].Height, name)
+			}
+
+			delete(sums, name)
+		}
+	}
+
+	// NOTE the files not in manifest are not allowed.
+	if len(sums) > 0 {
+		names := make([]string, 0, len(sums))
+
+		for name := range sums {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		return nil, e.Errorf("files not in manifest, %q", names)
+	}
+
+	return manifest, nil
+}
+
+func extractBlockArchiveFile(r io.Reader, directory, name string) (string, error) {
+	p := filepath.Join(directory, filepath.FromSlash(name))
+
+	if rel, err := filepath.Rel(directory, p); err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("invalid file name in archive, %q", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	f, err := os.OpenFile(filepath.Clean(p), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(f, io.TeeReader(r, h)); err != nil { //nolint:gosec //...
+		return "", errors.WithStack(err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
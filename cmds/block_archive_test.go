@@ -0,0 +1,177 @@
+package cmds
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsBlockArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+
+	w, err := newBlockArchiveWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.close(blockArchiveManifest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name string, b []byte) string {
+		p := filepath.Join(dir, name)
+
+		if err := os.WriteFile(p, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		return p
+	}
+
+	cases := []struct {
+		name      string
+		path      string
+		isarchive bool
+		err       bool
+	}{
+		{name: "directory", path: dir},
+		{name: "archive", path: write("a.tar.zst", buf.Bytes()), isarchive: true},
+		{name: "not zstd", path: write("a.yml", []byte("network_id: a")), err: true},
+		{name: "empty file", path: write("empty", nil), err: true},
+		{name: "not found", path: filepath.Join(dir, "unknown"), err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			isarchive, err := isBlockArchive(c.path)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			case isarchive != c.isarchive:
+				t.Errorf("expected %v, got %v", c.isarchive, isarchive)
+			}
+		})
+	}
+}
+
+func TestExtractBlockArchive(t *testing.T) {
+	type file struct {
+		name string
+		body string
+	}
+
+	cases := []struct {
+		name     string
+		files    []file
+		manifest func(map[string]string) map[string]string
+		err      bool
+	}{
+		{
+			name:  "ok",
+			files: []file{{"000/a", "a"}, {"000/b", "b"}},
+		},
+		{
+			name:  "not in manifest",
+			files: []file{{"000/a", "a"}, {"000/b", "b"}},
+			manifest: func(m map[string]string) map[string]string {
+				delete(m, "000/b")
+
+				return m
+			},
+			err: true,
+		},
+		{
+			name:  "not in archive",
+			files: []file{{"000/a", "a"}},
+			manifest: func(m map[string]string) map[string]string {
+				m["000/c"] = m["000/a"]
+
+				return m
+			},
+			err: true,
+		},
+		{
+			name:  "checksum",
+			files: []file{{"000/a", "a"}},
+			manifest: func(m map[string]string) map[string]string {
+				m["000/a"] = "00"
+
+				return m
+			},
+			err: true,
+		},
+		{
+			name:  "outside",
+			files: []file{{"../a", "a"}},
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w, err := newBlockArchiveWriter(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sums := map[string]string{}
+
+			for _, f := range c.files {
+				sum, err := w.addBytes(f.name, []byte(f.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				sums[f.name] = sum
+			}
+
+			if c.manifest != nil {
+				sums = c.manifest(sums)
+			}
+
+			if err := w.close(blockArchiveManifest{Blocks: []blockArchiveBlock{{Files: sums}}}); err != nil {
+				t.Fatal(err)
+			}
+
+			archive := filepath.Join(t.TempDir(), "a.tar.zst")
+
+			if err := os.WriteFile(archive, buf.Bytes(), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+
+			_, err = extractBlockArchive(archive, dir)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			for _, f := range c.files {
+				switch b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.name))); {
+				case err != nil:
+					t.Errorf("file %q: %+v", f.name, err)
+				case string(b) != f.body:
+					t.Errorf("file %q: expected %q, got %q", f.name, f.body, b)
+				}
+			}
+		})
+	}
+}
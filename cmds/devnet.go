@@ -0,0 +1,394 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	devnetNodeStopTimeout    = time.Second * 10
+	devnetMongodReadyTimeout = time.Second * 30
+)
+
+type DevnetCommand struct { //nolint:govet //...
+	Up DevnetUpCommand `cmd:"" help:"generate designs and run local nodes"`
+}
+
+// DevnetUpCommand runs the local nodes as the subprocesses of node binary. The
+// designs are generated under directory at first and reused until --fresh.
+type DevnetUpCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseCommand
+	Nodes        uint64 `name:"nodes" help:"number of nodes" default:"4"`
+	SuffrageSize uint64 `name:"suffrage-size" help:"number of genesis suffrage nodes; the other nodes are sync-only and can join suffrage later; default is all nodes" placeholder:"size"`
+	Dir          string `name:"dir" help:"devnet directory" default:"./devnet" type:"path" placeholder:"directory"`
+	NetworkID    string `name:"network-id" help:"network-id" default:"${network_id}"`
+	Host         string `name:"host" help:"publish host of nodes" default:"127.0.0.1"`
+	Port         uint64 `name:"port" help:"network port of first node; next nodes use the next ports" default:"4320"`
+	APIPort      uint64 `name:"api-port" help:"digest api port of first node; next nodes use the next ports" default:"54320"`
+	Currency     string `name:"currency" help:"genesis currency id" default:"MCC"`
+	Supply       string `name:"supply" help:"initial supply of genesis currency" default:"1000000000000000000000"`
+	Threshold    string `name:"threshold" help:"consensus threshold; under 100, the nodes can be stopped for suffrage flows" default:"67"`
+	Digest       bool   `name:"digest" help:"run digest api; without --database, local mongod is started for digest"`
+	Database     string `name:"database" help:"mongodb uri for digest; default is local mongod" placeholder:"URI"`
+	Mongod       string `name:"mongod" help:"mongod binary of local mongodb" default:"mongod" placeholder:"file"`
+	MongoPort    uint64 `name:"mongo-port" help:"port of local mongodb" default:"27027"`
+	Fresh        bool   `name:"fresh" help:"regenerate designs and remove storages"`
+	Binary       string `name:"binary" help:"node binary; default is this binary" type:"existingfile"`
+	//revive:enable:line-length-limit
+}
+
+type devnetNode struct {
+	design launch.NodeDesign
+	file   string
+	log    string
+}
+
+func (cmd *DevnetUpCommand) Run(pctx context.Context) error {
+	if _, err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	if len(cmd.Binary) < 1 {
+		i, err := os.Executable()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		cmd.Binary = i
+	}
+
+	dir, err := filepath.Abs(cmd.Dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cmd.Dir = dir
+
+	if err := cmd.prepareDesigns(); err != nil {
+		return err
+	}
+
+	nodes, err := cmd.loadNodes()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(pctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if cmd.isLocalMongo() {
+		wait, err := cmd.startMongod(ctx)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			cancel()
+			wait()
+		}()
+	}
+
+	if err := cmd.init(ctx, nodes[0]); err != nil {
+		return err
+	}
+
+	return cmd.run(ctx, cancel, nodes)
+}
+
+func (cmd *DevnetUpCommand) prepareDesigns() error {
+	switch _, err := os.Stat(filepath.Join(cmd.Dir, genesisDesignFile)); {
+	case err == nil:
+		if !cmd.Fresh {
+			cmd.Log.Debug().Str("dir", cmd.Dir).Msg("designs found; reuse")
+
+			return nil
+		}
+	case !os.IsNotExist(err):
+		return errors.WithStack(err)
+	}
+
+	for _, i := range []string{"storage", "mongodb"} {
+		if err := os.RemoveAll(filepath.Join(cmd.Dir, i)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	b := cmd.Digest
+
+	spec := genesisSpec{
+		NetworkID:    cmd.NetworkID,
+		Host:         cmd.Host,
+		Storage:      filepath.Join(cmd.Dir, "storage"),
+		Database:     cmd.database(),
+		Threshold:    cmd.Threshold,
+		Digest:       &b,
+		Nodes:        cmd.Nodes,
+		SuffrageSize: cmd.SuffrageSize,
+		Port:         cmd.Port,
+		APIPort:      cmd.APIPort,
+		Currencies: []genesisSpecCurrency{
+			{ID: cmd.Currency, InitialSupply: cmd.Supply},
+		},
+	}
+
+	spec.defaults()
+
+	if err := spec.isValid(); err != nil {
+		return err
+	}
+
+	g, err := spec.generate(cmd.Encoder)
+	if err != nil {
+		return err
+	}
+
+	files, err := g.write(cmd.Dir, true)
+	if err != nil {
+		return err
+	}
+
+	cmd.Log.Debug().Strs("files", files).Msg("designs generated")
+
+	return nil
+}
+
+func (cmd *DevnetUpCommand) isLocalMongo() bool {
+	return cmd.Digest && len(cmd.Database) < 1
+}
+
+func (cmd *DevnetUpCommand) database() string {
+	if len(cmd.Database) > 0 {
+		return cmd.Database
+	}
+
+	return fmt.Sprintf("mongodb://127.0.0.1:%d", cmd.MongoPort)
+}
+
+// startMongod runs the local mongodb for digest under directory and waits
+// until it accepts connections; the returned func waits until mongod stopped.
+func (cmd *DevnetUpCommand) startMongod(ctx context.Context) (func(), error) {
+	dbpath := filepath.Join(cmd.Dir, "mongodb")
+
+	if err := os.MkdirAll(dbpath, 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	log := filepath.Join(cmd.Dir, "mongod.log")
+
+	c := exec.CommandContext(ctx, cmd.Mongod, //nolint:gosec //...
+		"--dbpath", dbpath,
+		"--bind_ip", "127.0.0.1",
+		"--port", fmt.Sprintf("%d", cmd.MongoPort),
+		"--logpath", log,
+	)
+	c.Cancel = func() error {
+		return c.Process.Signal(os.Interrupt)
+	}
+	c.WaitDelay = devnetNodeStopTimeout
+
+	if err := c.Start(); err != nil {
+		return nil, errors.WithMessagef(err, "start mongod, %q; install mongod or use --database", cmd.Mongod)
+	}
+
+	exited := make(chan error, 1)
+
+	go func() {
+		exited <- c.Wait()
+	}()
+
+	wait := func() {
+		<-exited
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", cmd.MongoPort)
+
+	ticker := time.NewTicker(time.Millisecond * 300) //nolint:gomnd //...
+	defer ticker.Stop()
+
+	timeout := time.After(devnetMongodReadyTimeout)
+
+	for {
+		select {
+		case <-ctx.Done():
+			wait()
+
+			return nil, ctx.Err()
+		case err := <-exited:
+			return nil, errors.Errorf("mongod stopped; see %q: %v", log, err)
+		case <-timeout:
+			_ = c.Process.Kill()
+			wait()
+
+			return nil, errors.Errorf("mongod not ready; see %q", log)
+		case <-ticker.C:
+			conn, err := net.DialTimeout("tcp", addr, time.Second)
+			if err != nil {
+				continue
+			}
+
+			_ = conn.Close()
+
+			cmd.print("mongod: pid=%d database=%s log=%s", c.Process.Pid, cmd.database(), log)
+
+			return wait, nil
+		}
+	}
+}
+
+// loadNodes loads the node designs of the nodes in keys file.
+func (cmd *DevnetUpCommand) loadNodes() ([]devnetNode, error) {
+	b, err := os.ReadFile(filepath.Join(cmd.Dir, genesisKeysFile))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var u struct {
+		Nodes []struct {
+			Address string `yaml:"address"`
+		} `yaml:"nodes"`
+	}
+
+	if err := yaml.Unmarshal(b, &u); err != nil {
+		return nil, errors.WithMessagef(err, "keys file")
+	}
+
+	if len(u.Nodes) < 1 {
+		return nil, errors.Errorf("empty nodes in keys file")
+	}
+
+	nodes := make([]devnetNode, len(u.Nodes))
+
+	for i := range u.Nodes {
+		f := filepath.Join(cmd.Dir, u.Nodes[i].Address+".yml")
+
+		d, _, err := launch.NodeDesignFromFile(f, cmd.Encoder)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[i] = devnetNode{
+			design: d,
+			file:   f,
+			log:    filepath.Join(cmd.Dir, u.Nodes[i].Address+".log"),
+		}
+	}
+
+	return nodes, nil
+}
+
+// init creates genesis block by the genesis node; if storage of genesis node
+// exists, init is skipped.
+func (cmd *DevnetUpCommand) init(ctx context.Context, node devnetNode) error {
+	switch _, err := os.Stat(node.design.Storage.Base); {
+	case err == nil:
+		cmd.Log.Debug().Str("storage", node.design.Storage.Base).Msg("storage found; init skipped")
+
+		return nil
+	case !os.IsNotExist(err):
+		return errors.WithStack(err)
+	}
+
+	log := filepath.Join(cmd.Dir, "init.log")
+
+	c := exec.CommandContext(ctx, cmd.Binary, //nolint:gosec //...
+		"init",
+		"--design="+node.file,
+		"--log.out="+log,
+		filepath.Join(cmd.Dir, genesisDesignFile),
+	)
+
+	if err := c.Run(); err != nil {
+		return errors.WithMessagef(err, "init %q; see %q", node.design.Address, log)
+	}
+
+	cmd.print("genesis block created by %s", node.design.Address)
+
+	return nil
+}
+
+// run starts the nodes and waits until all the nodes are stopped. The stopped
+// node does not stop the other nodes, so nodes can be killed and restarted to
+// test the suffrage flows.
+func (cmd *DevnetUpCommand) run(ctx context.Context, cancel func(), nodes []devnetNode) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i := range nodes {
+		node := nodes[i]
+
+		c := exec.CommandContext(ctx, cmd.Binary, cmd.runArgs(i, nodes)...) //nolint:gosec //...
+		c.Cancel = func() error {
+			return c.Process.Signal(os.Interrupt)
+		}
+		c.WaitDelay = devnetNodeStopTimeout
+
+		if err := c.Start(); err != nil {
+			cancel()
+
+			return errors.WithMessagef(err, "start %q", node.design.Address)
+		}
+
+		cmd.print("%s: pid=%d publish=%s log=%s",
+			node.design.Address, c.Process.Pid, node.design.Network.PublishString, node.log)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := c.Wait()
+
+			switch {
+			case ctx.Err() != nil:
+				cmd.Log.Debug().Interface("node", node.design.Address).Msg("node stopped")
+			case err != nil:
+				cmd.Log.Error().Err(err).Interface("node", node.design.Address).Str("log", node.log).Msg("node stopped")
+			default:
+				cmd.Log.Debug().Interface("node", node.design.Address).Msg("node exited")
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (*DevnetUpCommand) runArgs(i int, nodes []devnetNode) []string {
+	node := nodes[i]
+
+	args := []string{
+		"run",
+		"--design=" + node.file,
+		"--dev.allow-consensus",
+		"--log.format=json",
+		"--log.out=" + node.log,
+	}
+
+	for j := range nodes {
+		if j == i {
+			continue
+		}
+
+		publish := nodes[j].design.Network.PublishString
+
+		if nodes[j].design.Network.TLSInsecure {
+			publish += "#tls_insecure"
+		}
+
+		args = append(args, fmt.Sprintf("--discovery=%s", publish))
+	}
+
+	return args
+}
@@ -0,0 +1,438 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	csteps "github.com/imfact-labs/currency-model/app/runtime/steps"
+	"github.com/imfact-labs/imfact-model/runtime/steps"
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	isaacblock "github.com/imfact-labs/mitum2/isaac/block"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/imfact-labs/mitum2/util/localtime"
+	"github.com/imfact-labs/mitum2/util/logging"
+	"github.com/imfact-labs/mitum2/util/ps"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var PNameExportBlocks = ps.Name("export-blocks")
+
+type ExportCommand struct { //nolint:govet //...
+	// revive:disable:line-length-limit
+	launch.DesignFlag
+	Output      string           `arg:"" name:"output" help:"block archive file to export" type:"path"`
+	HeightRange launch.RangeFlag `name:"range" help:"<from>-<to>" default:""`
+	launch.PrivatekeyFlags
+	Force           bool `name:"force" help:"overwrite the existing output file"`
+	log             *zerolog.Logger
+	launch.DevFlags `embed:"" prefix:"dev."`
+	fromHeight      base.Height
+	toHeight        base.Height
+	// revive:enable:line-length-limit
+}
+
+func (cmd *ExportCommand) Run(pctx context.Context) error {
+	var log *logging.Logging
+	if err := util.LoadFromContextOK(pctx, launch.LoggingContextKey, &log); err != nil {
+		return err
+	}
+
+	cmd.log = log.Log()
+
+	if err := cmd.prepare(); err != nil {
+		return err
+	}
+
+	cmd.log.Debug().
+		Interface("design", cmd.DesignFlag).
+		Interface("privatekey", cmd.PrivatekeyFlags).
+		Interface("dev", cmd.DevFlags).
+		Str("output", cmd.Output).
+		Interface("from_height", cmd.fromHeight).
+		Interface("to_height", cmd.toHeight).
+		Bool("force", cmd.Force).
+		Msg("flags")
+
+	nctx := util.ContextWithValues(pctx, map[util.ContextKey]interface{}{
+		launch.DesignFlagContextKey: cmd.DesignFlag,
+		launch.DevFlagsContextKey:   cmd.DevFlags,
+		launch.PrivatekeyContextKey: string(cmd.PrivatekeyFlags.Flag.Body()),
+	})
+
+	pps := ps.NewPS("cmd-export")
+	_ = pps.SetLogging(log)
+
+	_ = pps.
+		AddOK(launch.PNameEncoder, csteps.PEncoder, nil).
+		AddOK(launch.PNameDesign, launch.PLoadDesign, nil, launch.PNameEncoder).
+		AddOK(launch.PNameLocal, launch.PLocal, nil, launch.PNameDesign).
+		AddOK(launch.PNameBlockItemReaders, launch.PBlockItemReaders, nil, launch.PNameDesign).
+		AddOK(launch.PNameStorage, launch.PStorage, launch.PCloseStorage, launch.PNameLocal)
+
+	_ = pps.POK(launch.PNameEncoder).
+		PostAddOK(launch.PNameAddHinters, steps.PAddHinters)
+
+	_ = pps.POK(launch.PNameDesign).
+		PostAddOK(launch.PNameCheckDesign, launch.PCheckDesign)
+
+	_ = pps.POK(launch.PNameBlockItemReaders).
+		PreAddOK(launch.PNameBlockItemReadersDecompressFunc, launch.PBlockItemReadersDecompressFunc).
+		PostAddOK(launch.PNameRemotesBlockItemReaderFunc, launch.PRemotesBlockItemReaderFunc)
+
+	_ = pps.POK(launch.PNameStorage).
+		PreAddOK(launch.PNameCheckLocalFS, launch.PCheckLocalFS).
+		PreAddOK(launch.PNameLoadDatabase, launch.PLoadDatabase).
+		PostAddOK(launch.PNameCheckLeveldbStorage, launch.PCheckLeveldbStorage).
+		PostAddOK(launch.PNameLoadFromDatabase, launch.PLoadFromDatabase).
+		PostAddOK(launch.PNameCheckBlocksOfStorage, launch.PCheckBlocksOfStorage).
+		PostAddOK(launch.PNamePatchBlockItemReaders, launch.PPatchBlockItemReaders).
+		PostAddOK(launch.PNameNodeInfo, launch.PNodeInfo).
+		PostAddOK(PNameExportBlocks, cmd.pExportBlocks)
+
+	cmd.log.Debug().Interface("process", pps.Verbose()).Msg("process ready")
+
+	nctx, err := pps.Run(nctx)
+	defer func() {
+		cmd.log.Debug().Interface("process", pps.Verbose()).Msg("process will be closed")
+
+		if _, err = pps.Close(nctx); err != nil {
+			cmd.log.Error().Err(err).Msg("failed to close")
+		}
+	}()
+
+	return err
+}
+
+func (cmd *ExportCommand) prepare() error {
+	cmd.fromHeight, cmd.toHeight = base.NilHeight, base.NilHeight
+
+	if h := cmd.HeightRange.From(); h != nil {
+		cmd.fromHeight = base.Height(*h)
+
+		if err := cmd.fromHeight.IsValid(nil); err != nil {
+			return errors.WithMessagef(err, "invalid from height; from=%d", *h)
+		}
+	}
+
+	if h := cmd.HeightRange.To(); h != nil {
+		cmd.toHeight = base.Height(*h)
+
+		if err := cmd.toHeight.IsValid(nil); err != nil {
+			return errors.WithMessagef(err, "invalid to height; to=%d", *h)
+		}
+
+		if cmd.fromHeight > cmd.toHeight {
+			return errors.Errorf("from height is higher than to; from=%d to=%d", cmd.fromHeight, cmd.toHeight)
+		}
+	}
+
+	switch fi, err := os.Stat(cmd.Output); {
+	case os.IsNotExist(err):
+	case err != nil:
+		return errors.WithStack(err)
+	case fi.IsDir():
+		return errors.Errorf("output is directory, %q", cmd.Output)
+	case !cmd.Force:
+		return errors.Errorf("output already exists, %q; use --force to overwrite", cmd.Output)
+	}
+
+	return nil
+}
+
+func (cmd *ExportCommand) pExportBlocks(pctx context.Context) (context.Context, error) {
+	e := util.StringError("export blocks")
+
+	var design launch.NodeDesign
+	var isaacparams *isaac.Params
+	var newReaders func(context.Context, string, *isaac.BlockItemReadersArgs) (*isaac.BlockItemReaders, error)
+
+	if err := util.LoadFromContextOK(pctx,
+		launch.DesignContextKey, &design,
+		launch.ISAACParamsContextKey, &isaacparams,
+		launch.NewBlockItemReadersFuncContextKey, &newReaders,
+	); err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	readers, err := newReaders(pctx, launch.LocalFSDataDirectory(design.Storage.Base), nil)
+	if err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	var last base.Height
+
+	switch fromHeight, _, i, err := checkLastHeight(pctx, readers.Root(), cmd.fromHeight, cmd.toHeight); {
+	case err != nil:
+		return pctx, e.Wrap(err)
+	default:
+		cmd.fromHeight = fromHeight
+		last = i
+
+		cmd.log.Debug().
+			Interface("from_height", cmd.fromHeight).
+			Interface("last", last).
+			Msg("heights checked")
+	}
+
+	// NOTE write to temp file first, and then rename to output.
+	temp := cmd.Output + ".tmp"
+
+	if err := cmd.export(pctx, readers, temp, last, isaacparams.NetworkID()); err != nil {
+		_ = os.Remove(temp)
+
+		return pctx, e.Wrap(err)
+	}
+
+	if err := os.Rename(temp, cmd.Output); err != nil {
+		return pctx, e.Wrap(errors.WithStack(err))
+	}
+
+	cmd.log.Info().
+		Str("output", cmd.Output).
+		Interface("from_height", cmd.fromHeight).
+		Interface("to_height", last).
+		Msg("blocks exported")
+
+	return pctx, nil
+}
+
+func (cmd *ExportCommand) export(
+	pctx context.Context,
+	readers *isaac.BlockItemReaders,
+	output string,
+	last base.Height,
+	networkID base.NetworkID,
+) error {
+	f, err := os.OpenFile(filepath.Clean(output), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	w, err := newBlockArchiveWriter(f)
+	if err != nil {
+		return err
+	}
+
+	manifest := blockArchiveManifest{
+		NetworkID: string(networkID),
+		From:      cmd.fromHeight,
+		To:        last,
+	}
+
+	for height := cmd.fromHeight; height <= last; height++ {
+		block, err := cmd.exportBlock(pctx, w, readers, height)
+		if err != nil {
+			return errors.WithMessagef(err, "height %d", height)
+		}
+
+		manifest.Blocks = append(manifest.Blocks, block)
+
+		cmd.log.Debug().Interface("height", height).Msg("block exported")
+	}
+
+	manifest.CreatedAt = localtime.Now().UTC()
+
+	if err := w.close(manifest); err != nil {
+		return err
+	}
+
+	return errors.WithStack(f.Sync())
+}
+
+func (cmd *ExportCommand) exportBlock(
+	pctx context.Context,
+	w *blockArchiveWriter,
+	readers *isaac.BlockItemReaders,
+	height base.Height,
+) (block blockArchiveBlock, _ error) {
+	var encs *encoder.Encoders
+	var fromRemotes isaac.RemotesBlockItemReadFunc
+
+	if err := util.LoadFromContextOK(pctx,
+		launch.EncodersContextKey, &encs,
+		launch.RemotesBlockItemReaderFuncContextKey, &fromRemotes,
+	); err != nil {
+		return block, err
+	}
+
+	block.Height = height
+	block.Files = map[string]string{}
+
+	var bfiles base.BlockItemFiles
+
+	switch i, found, err := readers.ItemFiles(height); {
+	case err != nil:
+		return block, err
+	case !found:
+		return block, util.ErrNotFound.Errorf("block item files")
+	default:
+		bfiles = i
+	}
+
+	switch i, found, err := isaac.BlockItemReadersDecode[base.BlockMap](readers.Item, height, base.BlockItemMap, nil); {
+	case err != nil:
+		return block, err
+	case !found:
+		return block, util.ErrNotFound.Errorf("blockmap")
+	default:
+		b, err := util.MarshalJSON(i)
+		if err != nil {
+			return block, err
+		}
+
+		block.BlockMap = b
+	}
+
+	heightdir := isaac.BlockHeightDirectory(height)
+
+	// NOTE the item files are exported as local item files of the copied file
+	// names, so the block item files are rebuilt.
+	maker := isaac.NewBlockItemFilesMaker(encs.JSON())
+
+	for t, bf := range bfiles.Items() {
+		var name string
+		var sum string
+
+		switch {
+		case isaac.IsInLocalBlockItemFile(bf.URI()):
+			i, j, err := cmd.exportLocalItem(w, readers, height, bf)
+			if err != nil {
+				return block, errors.WithMessagef(err, "item %q", t)
+			}
+
+			name, sum = i, j
+		default:
+			i, j, err := cmd.exportRemoteItem(pctx, w, fromRemotes, height, t, bf)
+			if err != nil {
+				return block, errors.WithMessagef(err, "item %q", t)
+			}
+
+			name, sum = i, j
+		}
+
+		if _, err := maker.SetItem(t, isaac.NewLocalFSBlockItemFile(filepath.Base(name), bf.CompressFormat())); err != nil {
+			return block, err
+		}
+
+		block.Files[name] = sum
+	}
+
+	b, err := maker.Bytes()
+	if err != nil {
+		return block, err
+	}
+
+	name := blockArchivePath(filepath.Dir(heightdir), base.BlockItemFilesName(height))
+
+	switch sum, err := w.addBytes(name, b); {
+	case err != nil:
+		return block, err
+	default:
+		block.Files[name] = sum
+	}
+
+	return block, nil
+}
+
+func (*ExportCommand) exportLocalItem(
+	w *blockArchiveWriter,
+	readers *isaac.BlockItemReaders,
+	height base.Height,
+	bf base.BlockItemFile,
+) (name, sum string, _ error) {
+	f, found, err := readers.ReadFileFromItemFile(height, bf)
+
+	switch {
+	case err != nil:
+		return name, sum, err
+	case !found:
+		return name, sum, util.ErrNotFound.Errorf("item file, %q", bf.URI().Path)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return name, sum, errors.WithStack(err)
+	}
+
+	name = blockArchivePath(isaac.BlockHeightDirectory(height), filepath.Base(bf.URI().Path))
+
+	sum, err = w.add(name, fi.Size(), f)
+
+	return name, sum, err
+}
+
+func (*ExportCommand) exportRemoteItem(
+	pctx context.Context,
+	w *blockArchiveWriter,
+	fromRemotes isaac.RemotesBlockItemReadFunc,
+	height base.Height,
+	t base.BlockItemType,
+	bf base.BlockItemFile,
+) (name, sum string, _ error) {
+	uri := bf.URI()
+
+	buf := bytes.NewBuffer(nil)
+	defer buf.Reset()
+
+	switch known, found, err := fromRemotes(pctx, uri, bf.CompressFormat(),
+		func(r io.Reader, _ string) error {
+			_, err := io.Copy(buf, r)
+
+			return errors.WithStack(err)
+		},
+	); {
+	case err != nil:
+		return name, sum, err
+	case !known:
+		return name, sum, errors.Errorf("unknown remote item file, %q", &uri)
+	case !found:
+		return name, sum, util.ErrNotFound.Errorf("remote item file, %q", &uri)
+	}
+
+	var dr io.Reader
+
+	switch i, err := util.DefaultDecompressReaderFunc(bf.CompressFormat()); {
+	case err != nil:
+		return name, sum, err
+	default:
+		j, err := i(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			return name, sum, err
+		}
+
+		dr = j
+	}
+
+	switch _, _, enchint, err := isaac.LoadBlockItemFileBaseHeader(dr); {
+	case errors.Is(err, io.EOF):
+		return name, sum, util.ErrNotFound.Errorf("item file header, %q", t)
+	case err != nil:
+		return name, sum, err
+	default:
+		i, err := isaacblock.BlockItemFileName(t, enchint.Type(), bf.CompressFormat())
+		if err != nil {
+			return name, sum, err
+		}
+
+		name = blockArchivePath(isaac.BlockHeightDirectory(height), i)
+	}
+
+	sum, err := w.addBytes(name, buf.Bytes())
+
+	return name, sum, err
+}
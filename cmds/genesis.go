@@ -0,0 +1,604 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/imfact-labs/currency-model/common"
+	"github.com/imfact-labs/currency-model/operation/currency"
+	isaacoperation "github.com/imfact-labs/currency-model/operation/isaac"
+	currencytypes "github.com/imfact-labs/currency-model/types"
+	"github.com/imfact-labs/mitum2/base"
+	isaacnetwork "github.com/imfact-labs/mitum2/isaac/network"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	genesisDesignFile = "genesis-design.yml"
+	genesisKeysFile   = "keys.yml"
+)
+
+type GenesisCommand struct { //nolint:govet //...
+	New   GenesisNewCommand   `cmd:"" help:"generate node designs and genesis design from spec"`
+	Check GenesisCheckCommand `cmd:"" help:"check genesis design with node designs"`
+}
+
+type GenesisNewCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseCommand
+	Spec   string `arg:"" name:"spec" help:"genesis spec file; digest is enabled by default and needs running mongodb at database" type:"existingfile"`
+	Output string `name:"output" help:"output directory" default:"." type:"path" placeholder:"directory"`
+	Force  bool   `name:"force" help:"overwrite existing files"`
+	//revive:enable:line-length-limit
+}
+
+// genesisSpec is the short spec to generate the node designs and genesis
+// design.
+type genesisSpec struct {
+	NetworkID    string                `yaml:"network_id"`
+	NodePrefix   string                `yaml:"node_prefix"`
+	Host         string                `yaml:"host"`
+	Storage      string                `yaml:"storage"`
+	Database     string                `yaml:"database"`
+	Threshold    string                `yaml:"threshold"`
+	Digest       *bool                 `yaml:"digest"`
+	Account      genesisSpecAccount    `yaml:"genesis_account"`
+	Currencies   []genesisSpecCurrency `yaml:"currencies"`
+	Policy       genesisSpecPolicy     `yaml:"policy"`
+	Nodes        uint64                `yaml:"nodes"`
+	SuffrageSize uint64                `yaml:"suffrage_size"`
+	Port         uint64                `yaml:"port"`
+	APIPort      uint64                `yaml:"api_port"`
+}
+
+type genesisSpecPolicy struct {
+	EmptyProposalNoBlock      *bool  `yaml:"empty_proposal_no_block"`
+	MaxOperationsInProposal   uint64 `yaml:"max_operations_in_proposal"`
+	SuffrageCandidateLifespan uint64 `yaml:"suffrage_candidate_lifespan"`
+	SuffrageCandidateLimit    uint64 `yaml:"suffrage_candidate_limit"`
+	SuffrageExpelLifespan     uint64 `yaml:"suffrage_expel_lifespan"`
+}
+
+type genesisSpecAccount struct {
+	Keys      []string `yaml:"keys"`
+	Threshold uint     `yaml:"threshold"`
+}
+
+type genesisSpecCurrency struct {
+	ID            string           `yaml:"currency_id"`
+	InitialSupply string           `yaml:"initial_supply"`
+	Decimal       string           `yaml:"decimal"`
+	MinBalance    string           `yaml:"min_balance"`
+	Feeer         genesisSpecFeeer `yaml:"feeer"`
+}
+
+type genesisSpecFeeer struct {
+	Type     string `yaml:"type"`
+	Amount   string `yaml:"amount"`
+	Receiver string `yaml:"receiver"`
+}
+
+type genesisNode struct {
+	priv    base.Privatekey
+	address base.Address
+}
+
+func (cmd *GenesisNewCommand) Run(pctx context.Context) error {
+	if _, err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	spec, err := loadGenesisSpec(cmd.Spec)
+	if err != nil {
+		return err
+	}
+
+	g, err := spec.generate(cmd.Encoder)
+	if err != nil {
+		return err
+	}
+
+	files, err := g.write(cmd.Output, cmd.Force)
+	if err != nil {
+		return err
+	}
+
+	for i := range files {
+		cmd.print("%s", files[i])
+	}
+
+	return nil
+}
+
+// generatedGenesis is the generated genesis design, node designs and keys by
+// file name.
+type generatedGenesis struct {
+	files     map[string][]byte
+	nodeFiles []string
+}
+
+func (spec genesisSpec) generate(enc encoder.Encoder) (generatedGenesis, error) {
+	var g generatedGenesis
+
+	nodes := make([]genesisNode, spec.Nodes)
+
+	for i := range nodes {
+		nodes[i] = genesisNode{
+			priv:    base.NewMPrivatekey(),
+			address: base.NewStringAddress(fmt.Sprintf("%s%d", spec.NodePrefix, i)),
+		}
+	}
+
+	accountPrivs, accountKeys, err := spec.genesisAccountKeys(enc)
+	if err != nil {
+		return g, err
+	}
+
+	files := map[string]interface{}{}
+
+	genesis, err := spec.genesisDesign(nodes, accountKeys)
+	if err != nil {
+		return g, err
+	}
+
+	files[genesisDesignFile] = genesis
+
+	g.nodeFiles = make([]string, len(nodes))
+
+	for i := range nodes {
+		g.nodeFiles[i] = nodes[i].address.String() + ".yml"
+		files[g.nodeFiles[i]] = spec.nodeDesign(i, nodes)
+	}
+
+	files[genesisKeysFile] = genesisKeys(nodes, accountPrivs, accountKeys)
+
+	g.files = map[string][]byte{}
+
+	for k := range files {
+		b, err := yaml.Marshal(files[k])
+		if err != nil {
+			return g, errors.WithStack(err)
+		}
+
+		g.files[k] = b
+	}
+
+	nodeDesigns := make([][]byte, len(g.nodeFiles))
+
+	for i := range g.nodeFiles {
+		nodeDesigns[i] = g.files[g.nodeFiles[i]]
+	}
+
+	// NOTE the generated designs should pass genesis check.
+	if report := checkGenesisDesign(enc, g.files[genesisDesignFile], nodeDesigns); !report.Valid {
+		_ = report.print(os.Stderr, "text")
+
+		return g, errors.Errorf("generated genesis design is not valid")
+	}
+
+	return g, nil
+}
+
+// write writes the files under directory and returns the written file paths.
+func (g generatedGenesis) write(dir string, force bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	names := sortedStringKeys(g.files)
+
+	if !force {
+		for _, k := range names {
+			switch _, err := os.Stat(filepath.Join(dir, k)); {
+			case err == nil:
+				return nil, errors.Errorf("file already exists, %q; use --force", filepath.Join(dir, k))
+			case !os.IsNotExist(err):
+				return nil, errors.WithStack(err)
+			}
+		}
+	}
+
+	files := make([]string, len(names))
+
+	for i, k := range names {
+		files[i] = filepath.Join(dir, k)
+
+		if err := os.WriteFile(files[i], g.files[k], 0o600); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return files, nil
+}
+
+func (spec genesisSpec) genesisAccountKeys(
+	enc encoder.Encoder,
+) ([]base.Privatekey, currencytypes.AccountKeys, error) {
+	var privs []base.Privatekey
+	pubs := make([]base.Publickey, len(spec.Account.Keys))
+
+	for i := range spec.Account.Keys {
+		pub, err := base.DecodePublickeyFromString(spec.Account.Keys[i], enc)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "genesis account key #%d", i)
+		}
+
+		pubs[i] = pub
+	}
+
+	if len(pubs) < 1 {
+		priv := base.NewMPrivatekey()
+
+		privs = append(privs, priv)
+		pubs = append(pubs, priv.Publickey())
+	}
+
+	keys := make([]currencytypes.AccountKey, len(pubs))
+
+	for i := range pubs {
+		k, err := currencytypes.NewBaseAccountKey(pubs[i], 100) //nolint:gomnd //...
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keys[i] = k
+	}
+
+	acks, err := currencytypes.NewBaseAccountKeys(keys, spec.Account.Threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privs, acks, nil
+}
+
+func loadGenesisSpec(f string) (genesisSpec, error) {
+	var spec genesisSpec
+
+	b, err := os.ReadFile(filepath.Clean(f))
+	if err != nil {
+		return spec, errors.WithStack(err)
+	}
+
+	nb, err := util.ReplaceEnvVariables(b)
+	if err != nil {
+		return spec, err
+	}
+
+	if err := yaml.Unmarshal(nb, &spec); err != nil {
+		return spec, errors.WithStack(err)
+	}
+
+	spec.defaults()
+
+	if err := spec.isValid(); err != nil {
+		return spec, err
+	}
+
+	return spec, nil
+}
+
+//revive:disable:cognitive-complexity
+func (spec *genesisSpec) defaults() {
+	set := func(s *string, v string) {
+		if len(*s) < 1 {
+			*s = v
+		}
+	}
+
+	setUint := func(s *uint64, v uint64) {
+		if *s < 1 {
+			*s = v
+		}
+	}
+
+	set(&spec.NetworkID, "mitum")
+	set(&spec.NodePrefix, "node")
+	set(&spec.Host, "127.0.0.1")
+	set(&spec.Storage, "./mitum-data")
+	set(&spec.Database, "mongodb://127.0.0.1:27017")
+	set(&spec.Threshold, "100")
+
+	setUint(&spec.Nodes, 1)
+	setUint(&spec.SuffrageSize, spec.Nodes)
+	setUint(&spec.Port, 4320)     //nolint:gomnd //...
+	setUint(&spec.APIPort, 54320) //nolint:gomnd //...
+
+	setUint(&spec.Policy.MaxOperationsInProposal, 99)          //nolint:gomnd //...
+	setUint(&spec.Policy.SuffrageCandidateLifespan, 333333333) //nolint:gomnd //...
+	setUint(&spec.Policy.SuffrageCandidateLimit, 1)
+	setUint(&spec.Policy.SuffrageExpelLifespan, 99) //nolint:gomnd //...
+
+	if spec.Policy.EmptyProposalNoBlock == nil {
+		b := true
+		spec.Policy.EmptyProposalNoBlock = &b
+	}
+
+	if spec.Digest == nil {
+		b := true
+		spec.Digest = &b
+	}
+
+	if spec.Account.Threshold < 1 {
+		spec.Account.Threshold = 100
+	}
+
+	for i := range spec.Currencies {
+		c := &spec.Currencies[i]
+
+		set(&c.Decimal, "9")
+		set(&c.MinBalance, "1")
+		set(&c.Feeer.Type, "fixed")
+
+		if c.Feeer.Type == "fixed" {
+			set(&c.Feeer.Amount, "1")
+		}
+	}
+}
+
+//revive:enable:cognitive-complexity
+
+func (spec genesisSpec) isValid() error {
+	e := util.ErrInvalid.Errorf("invalid genesis spec")
+
+	switch {
+	case spec.Port+spec.Nodes > 65535, spec.APIPort+spec.Nodes > 65535:
+		return e.Errorf("too high port")
+	case len(spec.Currencies) < 1:
+		return e.Errorf("empty currencies")
+	}
+
+	for i := range spec.Currencies {
+		c := spec.Currencies[i]
+
+		if len(c.ID) < 1 {
+			return e.Errorf("empty currency_id of currency #%d", i)
+		}
+
+		if _, err := common.NewBigFromString(c.InitialSupply); err != nil {
+			return e.Errorf("invalid initial_supply of %q: %v", c.ID, err)
+		}
+
+		switch c.Feeer.Type {
+		case "fixed", "nil":
+		default:
+			return e.Errorf("unknown feeer type of %q, %q; {fixed, nil}", c.ID, c.Feeer.Type)
+		}
+	}
+
+	return nil
+}
+
+// suffrageNodes is the number of nodes joined at genesis; with suffrage_size
+// less than nodes, the first suffrage_size nodes are joined and the rest are
+// sync-only nodes, which can join suffrage later.
+func (spec genesisSpec) suffrageNodes() uint64 {
+	if spec.SuffrageSize < spec.Nodes {
+		return spec.SuffrageSize
+	}
+
+	return spec.Nodes
+}
+
+func (spec genesisSpec) maxSuffrageSize() uint64 {
+	if spec.SuffrageSize < spec.Nodes {
+		return spec.Nodes
+	}
+
+	return spec.SuffrageSize
+}
+
+func (spec genesisSpec) genesisDesign(
+	nodes []genesisNode, keys currencytypes.AccountKeys,
+) (map[string]interface{}, error) {
+	account, err := currencytypes.NewAddressFromKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	joins := make([]interface{}, spec.suffrageNodes())
+
+	for i := range joins {
+		joins[i] = map[string]interface{}{
+			"_hint":     common.NodeHint.String(),
+			"address":   nodes[i].address.String(),
+			"publickey": nodes[i].priv.Publickey().String(),
+		}
+	}
+
+	aks := make([]interface{}, len(keys.Keys()))
+
+	for i, k := range keys.Keys() {
+		aks[i] = map[string]interface{}{
+			"_hint":  currencytypes.AccountKeyHint.String(),
+			"key":    k.Key().String(),
+			"weight": k.Weight(),
+		}
+	}
+
+	currencies := make([]interface{}, len(spec.Currencies))
+
+	for i := range spec.Currencies {
+		c := spec.Currencies[i]
+
+		feeer := map[string]interface{}{"_hint": currencytypes.NilFeeerHint.String()}
+
+		if c.Feeer.Type == "fixed" {
+			receiver := c.Feeer.Receiver
+			if len(receiver) < 1 {
+				receiver = account.String()
+			}
+
+			feeer = map[string]interface{}{
+				"_hint":    currencytypes.FixedFeeerHint.String(),
+				"receiver": receiver,
+				"amount":   c.Feeer.Amount,
+			}
+		}
+
+		currencies[i] = map[string]interface{}{
+			"_hint":           currencytypes.CurrencyDesignHint.String(),
+			"currency_id":     c.ID,
+			"initial_supply":  c.InitialSupply,
+			"total_supply":    c.InitialSupply,
+			"decimal":         c.Decimal,
+			"genesis_account": nil,
+			"policy": map[string]interface{}{
+				"_hint":       currencytypes.CurrencyPolicyHint.String(),
+				"min_balance": c.MinBalance,
+				"feeer":       feeer,
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"facts": []interface{}{
+			map[string]interface{}{
+				"_hint": isaacoperation.SuffrageGenesisJoinFactHint.String(),
+				"nodes": joins,
+			},
+			map[string]interface{}{
+				"_hint": isaacoperation.GenesisNetworkPolicyFactHint.String(),
+				"policy": map[string]interface{}{
+					"_hint":                       currencytypes.NetworkPolicyHint.String(),
+					"max_operations_in_proposal":  spec.Policy.MaxOperationsInProposal,
+					"suffrage_candidate_lifespan": spec.Policy.SuffrageCandidateLifespan,
+					"suffrage_candidate_limiter": map[string]interface{}{
+						"_hint": isaacoperation.FixedSuffrageCandidateLimiterRuleHint.String(),
+						"limit": spec.Policy.SuffrageCandidateLimit,
+					},
+					"max_suffrage_size":       spec.maxSuffrageSize(),
+					"suffrage_expel_lifespan": spec.Policy.SuffrageExpelLifespan,
+					"empty_proposal_no_block": *spec.Policy.EmptyProposalNoBlock,
+				},
+			},
+			map[string]interface{}{
+				"_hint":            currency.RegisterGenesisCurrencyFactHint.String(),
+				"genesis_node_key": nodes[0].priv.Publickey().String(),
+				"keys": map[string]interface{}{
+					"_hint":     currencytypes.AccountKeysHint.String(),
+					"keys":      aks,
+					"threshold": keys.Threshold(),
+				},
+				"currencies": currencies,
+			},
+		},
+	}, nil
+}
+
+// nodeDesign makes the node design like standalone.yml; with multiple nodes,
+// the other nodes are added to sync sources. Without digest, api is omitted.
+func (spec genesisSpec) nodeDesign(i int, nodes []genesisNode) map[string]interface{} {
+	n := nodes[i]
+	port := spec.Port + uint64(i)
+	apiPort := spec.APIPort + uint64(i)
+
+	storage := spec.Storage
+	database := spec.Database + "/mc?directConnection=true"
+
+	if len(nodes) > 1 {
+		storage = filepath.Join(spec.Storage, n.address.String())
+		database = fmt.Sprintf("%s/mc_%s?directConnection=true", spec.Database, n.address)
+	}
+
+	design := map[string]interface{}{
+		"address":    n.address.String(),
+		"privatekey": n.priv.String(),
+		"network_id": spec.NetworkID,
+		"network": map[string]interface{}{
+			"bind":         fmt.Sprintf("0.0.0.0:%d", port),
+			"publish":      fmt.Sprintf("%s:%d", spec.Host, port),
+			"tls_insecure": true,
+		},
+		"storage": map[string]interface{}{
+			"base": storage,
+		},
+		"parameters": map[string]interface{}{
+			"misc": map[string]interface{}{
+				"max_message_size":  3000000, //nolint:gomnd //...
+				"object_cache_size": 3000000, //nolint:gomnd //...
+			},
+			"isaac": map[string]interface{}{
+				"threshold":                       spec.Threshold,
+				"interval_broadcast_ballot":       "0.7s",
+				"wait_preparing_init_ballot":      "0.1s",
+				"min_wait_next_block_init_ballot": "3s",
+			},
+		},
+	}
+
+	if *spec.Digest {
+		design["api"] = map[string]interface{}{
+			"network": map[string]interface{}{
+				"bind": fmt.Sprintf("http://0.0.0.0:%d", apiPort),
+				"url":  fmt.Sprintf("http://%s:%d", spec.Host, apiPort),
+			},
+			"database": map[string]interface{}{
+				"uri": database,
+			},
+			"digest": true,
+		}
+	}
+
+	var sources []interface{}
+
+	for j := range nodes {
+		if j == i {
+			continue
+		}
+
+		sources = append(sources, map[string]interface{}{
+			"type":         string(isaacnetwork.SyncSourceTypeNode),
+			"address":      nodes[j].address.String(),
+			"publickey":    nodes[j].priv.Publickey().String(),
+			"publish":      fmt.Sprintf("%s:%d", spec.Host, spec.Port+uint64(j)),
+			"tls_insecure": true,
+		})
+	}
+
+	if len(sources) > 0 {
+		design["sync_sources"] = sources
+	}
+
+	return design
+}
+
+func genesisKeys(
+	nodes []genesisNode, accountPrivs []base.Privatekey, keys currencytypes.AccountKeys,
+) map[string]interface{} {
+	ns := make([]interface{}, len(nodes))
+
+	for i := range nodes {
+		ns[i] = map[string]interface{}{
+			"address":    nodes[i].address.String(),
+			"privatekey": nodes[i].priv.String(),
+			"publickey":  nodes[i].priv.Publickey().String(),
+		}
+	}
+
+	account := map[string]interface{}{}
+
+	if i, err := currencytypes.NewAddressFromKeys(keys); err == nil {
+		account["address"] = i.String()
+	}
+
+	if len(accountPrivs) > 0 {
+		privs := make([]string, len(accountPrivs))
+
+		for i := range accountPrivs {
+			privs[i] = accountPrivs[i].String()
+		}
+
+		account["privatekeys"] = privs
+	}
+
+	return map[string]interface{}{
+		"nodes":           ns,
+		"genesis_account": account,
+	}
+}
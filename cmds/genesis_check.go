@@ -0,0 +1,421 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/kong"
+	"github.com/imfact-labs/currency-model/operation/currency"
+	isaacoperation "github.com/imfact-labs/currency-model/operation/isaac"
+	currencytypes "github.com/imfact-labs/currency-model/types"
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/imfact-labs/mitum2/util/hint"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type GenesisCheckCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseCommand
+	GenesisDesign string   `arg:"" name:"genesis-design" help:"genesis design file" type:"existingfile"`
+	NodeDesigns   []string `arg:"" name:"node-design" help:"node design files; the nodes not in genesis suffrage are sync-only nodes" type:"existingfile"`
+	Format        string   `name:"format" help:"output format, {text, json}" default:"text"`
+	//revive:enable:line-length-limit
+}
+
+type genesisCheckReport struct {
+	GenesisNode string              `json:"genesis_node,omitempty"`
+	Facts       []string            `json:"facts"`
+	Nodes       []string            `json:"nodes"`
+	Issues      []genesisCheckIssue `json:"issues"`
+	Valid       bool                `json:"valid"`
+}
+
+type genesisCheckIssue struct {
+	Source string `json:"source"`
+	Check  string `json:"check"`
+	Error  string `json:"error"`
+}
+
+func (cmd *GenesisCheckCommand) Run(
+	kctx *kong.Context, pctx context.Context, //revive:disable-line:context-as-argument
+) error {
+	switch cmd.Format {
+	case "text", "json":
+	default:
+		return errors.Errorf("unsupported format, %q", cmd.Format)
+	}
+
+	if _, err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(filepath.Clean(cmd.GenesisDesign))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	nodes := make([][]byte, len(cmd.NodeDesigns))
+
+	for i := range cmd.NodeDesigns {
+		j, err := os.ReadFile(filepath.Clean(cmd.NodeDesigns[i]))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		nodes[i] = j
+	}
+
+	report := checkGenesisDesign(cmd.Encoder, b, nodes)
+
+	if err := report.print(cmd.Out, cmd.Format); err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		kctx.Exit(1)
+	}
+
+	return nil
+}
+
+// checkGenesisDesign decodes the facts of genesis design and checks them like
+// the genesis block generator does with the given node designs.
+func checkGenesisDesign(enc encoder.Encoder, genesis []byte, nodeDesigns [][]byte) genesisCheckReport {
+	report := genesisCheckReport{
+		Facts:  []string{},
+		Nodes:  []string{},
+		Issues: []genesisCheckIssue{},
+	}
+
+	add := func(source, check string, err error) {
+		report.Issues = append(report.Issues, genesisCheckIssue{Source: source, Check: check, Error: err.Error()})
+	}
+
+	designs := make([]launch.NodeDesign, 0, len(nodeDesigns))
+
+	for i := range nodeDesigns {
+		source := fmt.Sprintf("node design #%d", i)
+
+		var d launch.NodeDesign
+
+		if err := d.DecodeYAML(nodeDesigns[i], enc); err != nil {
+			add(source, "decode", err)
+
+			continue
+		}
+
+		if err := d.IsValid(nil); err != nil {
+			add(source, "is-valid", err)
+
+			continue
+		}
+
+		designs = append(designs, d)
+		report.Nodes = append(report.Nodes, d.Address.String())
+	}
+
+	var networkID base.NetworkID
+
+	for i := range designs {
+		switch {
+		case networkID == nil:
+			networkID = designs[i].NetworkID
+		case !networkID.Equal(designs[i].NetworkID):
+			add("node "+designs[i].Address.String(), "network-id",
+				errors.Errorf("different network id, %q != %q", designs[i].NetworkID, networkID))
+		}
+	}
+
+	facts, err := decodeGenesisFacts(enc, genesis)
+	if err != nil {
+		add("genesis design", "decode", err)
+	}
+
+	joined := genesisJoinedNodes(facts)
+
+	found := map[string]struct{}{}
+
+	for i := range facts {
+		fact := facts[i]
+
+		if fact == nil {
+			report.Facts = append(report.Facts, "")
+
+			continue
+		}
+
+		var ht hint.Hint
+
+		if hr, ok := fact.(hint.Hinter); ok {
+			ht = hr.Hint()
+		}
+
+		report.Facts = append(report.Facts, ht.String())
+
+		source := fmt.Sprintf("fact #%d %s", i, ht)
+
+		var kind string
+
+		switch {
+		case ht.IsCompatible(isaacoperation.SuffrageGenesisJoinFactHint):
+			kind = "join"
+		case ht.IsCompatible(isaacoperation.GenesisNetworkPolicyFactHint):
+			kind = "network-policy"
+		case ht.IsCompatible(currency.RegisterGenesisCurrencyFactHint):
+			kind = "register-genesis-currency"
+		default:
+			add(source, "fact-hint", errors.Errorf("not genesis fact"))
+
+			continue
+		}
+
+		if _, dup := found[kind]; dup {
+			add(source, "fact-hint", errors.Errorf("multiple %s facts", kind))
+
+			continue
+		}
+
+		found[kind] = struct{}{}
+
+		for _, err := range checkGenesisFact(fact, networkID, designs, joined, &report) {
+			add(source, kind, err)
+		}
+	}
+
+	for _, kind := range []string{"join", "network-policy", "register-genesis-currency"} {
+		if _, ok := found[kind]; !ok && err == nil {
+			add("genesis design", "fact-hint", errors.Errorf("missing %s fact", kind))
+		}
+	}
+
+	report.Valid = len(report.Issues) < 1
+
+	return report
+}
+
+func decodeGenesisFacts(enc encoder.Encoder, b []byte) ([]base.Fact, error) {
+	nb, err := util.ReplaceEnvVariables(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var u launch.GenesisDesignYAMLUnmarshaler
+
+	if err := yaml.Unmarshal(nb, &u); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	facts := make([]base.Fact, len(u.Facts))
+
+	for i := range u.Facts {
+		bj, err := util.MarshalJSON(u.Facts[i])
+		if err != nil {
+			return facts, err
+		}
+
+		if err := encoder.Decode(enc, bj, &facts[i]); err != nil {
+			return facts, errors.WithMessagef(err, "fact #%d", i)
+		}
+	}
+
+	return facts, nil
+}
+
+// genesisJoinedNodes returns the nodes of join fact.
+func genesisJoinedNodes(facts []base.Fact) []base.Node {
+	for i := range facts {
+		if t, ok := facts[i].(isaacoperation.SuffrageGenesisJoinFact); ok {
+			return t.Nodes()
+		}
+	}
+
+	return nil
+}
+
+func checkGenesisFact(
+	fact base.Fact,
+	networkID base.NetworkID,
+	designs []launch.NodeDesign,
+	joined []base.Node,
+	report *genesisCheckReport,
+) []error {
+	switch t := fact.(type) {
+	case isaacoperation.SuffrageGenesisJoinFact:
+		return checkGenesisJoinFact(t, networkID, designs)
+	case isaacoperation.GenesisNetworkPolicyFact:
+		return checkGenesisNetworkPolicyFact(t, joined)
+	case currency.RegisterGenesisCurrencyFact:
+		errs, genesisNode := checkGenesisCurrencyFact(t, networkID, designs, joined)
+		report.GenesisNode = genesisNode
+
+		return errs
+	default:
+		return []error{errors.Errorf("unknown genesis fact, %T", fact)}
+	}
+}
+
+func checkGenesisJoinFact(
+	fact isaacoperation.SuffrageGenesisJoinFact, networkID base.NetworkID, designs []launch.NodeDesign,
+) []error {
+	var errs []error
+
+	if err := isaacoperation.NewSuffrageGenesisJoinFact(fact.Nodes(), networkID).IsValid(networkID); err != nil {
+		errs = append(errs, err)
+	}
+
+	joined := map[string]base.Node{}
+
+	for _, n := range fact.Nodes() {
+		joined[n.Address().String()] = n
+	}
+
+	// NOTE the node designs not in genesis suffrage are sync-only nodes.
+	for i := range designs {
+		d := designs[i]
+
+		switch n, found := joined[d.Address.String()]; {
+		case !found:
+		case !n.Publickey().Equal(d.Privatekey.Publickey()):
+			errs = append(errs, errors.Errorf("publickey of node %q does not match with node design; %q != %q",
+				d.Address, n.Publickey(), d.Privatekey.Publickey()))
+		}
+
+		delete(joined, d.Address.String())
+	}
+
+	for _, k := range sortedStringKeys(joined) {
+		errs = append(errs, errors.Errorf("node %q of genesis suffrage not in node designs", k))
+	}
+
+	return errs
+}
+
+func checkGenesisNetworkPolicyFact(
+	fact isaacoperation.GenesisNetworkPolicyFact, joined []base.Node,
+) []error {
+	var errs []error
+
+	if err := isaacoperation.NewGenesisNetworkPolicyFact(fact.Policy()).IsValid(nil); err != nil {
+		errs = append(errs, err)
+	}
+
+	if fact.Policy() != nil && fact.Policy().MaxSuffrageSize() < uint64(len(joined)) {
+		errs = append(errs, errors.Errorf("max suffrage size, %d is less than genesis suffrage nodes, %d",
+			fact.Policy().MaxSuffrageSize(), len(joined)))
+	}
+
+	return errs
+}
+
+func checkGenesisCurrencyFact(
+	fact currency.RegisterGenesisCurrencyFact,
+	networkID base.NetworkID,
+	designs []launch.NodeDesign,
+	joined []base.Node,
+) ([]error, string) {
+	var errs []error
+	var genesisNode string
+
+	acks, err := currencytypes.NewBaseAccountKeys(fact.Keys().Keys(), fact.Keys().Threshold())
+	if err != nil {
+		return []error{err}, genesisNode
+	}
+
+	if err := currency.NewRegisterGenesisCurrencyFact(
+		networkID, fact.GenesisNodeKey(), acks, fact.Currencies()).IsValid(networkID); err != nil {
+		errs = append(errs, err)
+	}
+
+	for i := range designs {
+		if fact.GenesisNodeKey().Equal(designs[i].Privatekey.Publickey()) {
+			genesisNode = designs[i].Address.String()
+
+			break
+		}
+	}
+
+	if len(genesisNode) < 1 {
+		errs = append(errs, errors.Errorf("genesis node key, %q does not match with any node design",
+			fact.GenesisNodeKey()))
+	}
+
+	if joined != nil && util.CountFilteredSlice(joined, func(n base.Node) bool {
+		return n.Publickey().Equal(fact.GenesisNodeKey())
+	}) < 1 {
+		errs = append(errs, errors.Errorf("genesis node key, %q not in genesis suffrage", fact.GenesisNodeKey()))
+	}
+
+	account, err := currencytypes.NewAddressFromKeys(acks)
+	if err != nil {
+		return append(errs, err), genesisNode
+	}
+
+	cids := map[string]struct{}{}
+
+	for _, de := range fact.Currencies() {
+		cid := de.Currency().String()
+
+		if _, found := cids[cid]; found {
+			errs = append(errs, errors.Errorf("duplicated currency, %q", cid))
+		}
+
+		cids[cid] = struct{}{}
+
+		// NOTE only the genesis account exists in genesis block.
+		if f, ok := de.Policy().Feeer().(currencytypes.FixedFeeer); ok && !f.Receiver().Equal(account) {
+			errs = append(errs, errors.Errorf("feeer receiver of %q, %q is not genesis account, %q",
+				cid, f.Receiver(), account))
+		}
+	}
+
+	return errs, genesisNode
+}
+
+func (report genesisCheckReport) print(w io.Writer, format string) error {
+	if format == "json" {
+		b, err := util.MarshalJSONIndent(report)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(b))
+
+		return errors.WithStack(err)
+	}
+
+	status := "valid"
+	if !report.Valid {
+		status = "invalid"
+	}
+
+	_, _ = fmt.Fprintf(w, "genesis design: %s\n", status)
+	_, _ = fmt.Fprintf(w, "genesis node: %s\n", report.GenesisNode)
+	_, _ = fmt.Fprintln(w, "facts:")
+
+	for i := range report.Facts {
+		_, _ = fmt.Fprintf(w, "  - %s\n", report.Facts[i])
+	}
+
+	_, _ = fmt.Fprintln(w, "nodes:")
+
+	for i := range report.Nodes {
+		_, _ = fmt.Fprintf(w, "  - %s\n", report.Nodes[i])
+	}
+
+	if len(report.Issues) > 0 {
+		_, _ = fmt.Fprintln(w, "issues:")
+
+		for i := range report.Issues {
+			_, _ = fmt.Fprintf(w, "  - %s: [%s] %s\n", report.Issues[i].Source, report.Issues[i].Check, report.Issues[i].Error)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmds
+
+import "testing"
+
+func TestGenesisSpecSuffrage(t *testing.T) {
+	cases := []struct {
+		name         string
+		nodes        uint64
+		suffrageSize uint64
+		joined       uint64
+		max          uint64
+	}{
+		{name: "default", nodes: 4, joined: 4, max: 4},
+		{name: "same", nodes: 4, suffrageSize: 4, joined: 4, max: 4},
+		{name: "less than nodes", nodes: 4, suffrageSize: 1, joined: 1, max: 4},
+		{name: "more than nodes", nodes: 2, suffrageSize: 5, joined: 2, max: 5},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := genesisSpec{
+				Nodes:        c.nodes,
+				SuffrageSize: c.suffrageSize,
+				Currencies:   []genesisSpecCurrency{{ID: "MCC", InitialSupply: "100"}},
+			}
+
+			spec.defaults()
+
+			if err := spec.isValid(); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if i := spec.suffrageNodes(); i != c.joined {
+				t.Errorf("suffrage nodes: expected %d, got %d", c.joined, i)
+			}
+
+			if i := spec.maxSuffrageSize(); i != c.max {
+				t.Errorf("max suffrage size: expected %d, got %d", c.max, i)
+			}
+		})
+	}
+}
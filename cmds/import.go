@@ -1,7 +1,6 @@
 package cmds
 
 import (
-	"bytes"
 	"context"
 	"io"
 	"net/url"
@@ -12,12 +11,12 @@ import (
 	"github.com/imfact-labs/mitum2/base"
 	"github.com/imfact-labs/mitum2/isaac"
 	isaacblock "github.com/imfact-labs/mitum2/isaac/block"
+	isaacnetwork "github.com/imfact-labs/mitum2/isaac/network"
 	"github.com/imfact-labs/mitum2/launch"
 	"github.com/imfact-labs/mitum2/util"
 	"github.com/imfact-labs/mitum2/util/encoder"
 	"github.com/imfact-labs/mitum2/util/logging"
 	"github.com/imfact-labs/mitum2/util/ps"
-	"github.com/imfact-labs/mitum2/util/valuehash"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
@@ -30,12 +29,22 @@ var (
 type ImportCommand struct { //nolint:govet //...
 	// revive:disable:line-length-limit
 	launch.DesignFlag
-	Source      string           `arg:"" name:"source directory" help:"block data directory to import" type:"existingdir"`
+	Source      string           `arg:"" name:"source" help:"block data directory or block archive file to import; not needed with --from-node" type:"path" optional:""`
 	HeightRange launch.RangeFlag `name:"range" help:"<from>-<to>" default:""`
 	launch.PrivatekeyFlags
-	Do              bool   `name:"do" help:"really do import"`
-	CacheDirectory  string `name:"cache-directory" help:"directory for remote block item file"`
+	ProgressFlags
+	ValidateWorkersFlags
+	FromNode        launch.ConnInfoFlag `name:"from-node" help:"import blocks from remote node instead of source" placeholder:"ConnInfo"`
+	RemoteWorkers   int64               `name:"remote-workers" help:"number of workers to validate blocks of remote item files; 0 decides by workers" default:"0" placeholder:"count"`
+	Do              bool                `name:"do" help:"really do import"`
+	PlanFormat      string              `name:"plan-format" help:"output format of import plan without --do, {text, json}" default:"text"`
+	CacheDirectory  string              `name:"cache-directory" help:"directory for remote block item file; without it, temp directory is used and removed after import"`
+	CacheSize       ByteSizeFlag        `name:"cache-size" help:"max size of cache directory, like 10GiB; least recently used files are evicted" placeholder:"size"`
+	KeepCache       bool                `name:"keep-cache" help:"keep cache directory to reuse it in the next import; cache directory can be shared by nodes"`
+	Checkpoint      string              `name:"checkpoint" help:"checkpoint file to keep the import progress"`
+	Resume          bool                `name:"resume" help:"resume import from checkpoint"`
 	log             *zerolog.Logger
+	Out             io.Writer `kong:"-"`
 	launch.DevFlags `embed:"" prefix:"dev."`
 	fromHeight      base.Height
 	toHeight        base.Height
@@ -44,6 +53,15 @@ type ImportCommand struct { //nolint:govet //...
 	sourceReaders   *isaac.BlockItemReaders
 	toReaders       *isaac.BlockItemReaders
 	importedReaders *isaac.BlockItemReaders
+	checkpoint      *importCheckpoint
+	validateFrom    base.Height
+	sourceDirectory string
+	archiveManifest *blockArchiveManifest
+	cache           *remoteItemCache
+	cleanups        []func()
+	keepCache       bool
+	tempCache       bool
+	nodeClient      *isaacnetwork.BaseClient
 	// revive:enable:line-length-limit
 }
 
@@ -54,13 +72,18 @@ func (cmd *ImportCommand) Run(pctx context.Context) error {
 	}
 
 	cmd.log = log.Log()
+	cmd.Out = os.Stdout
+
+	defer cmd.cleanup()
 
 	if err := cmd.prepare(); err != nil {
 		return err
 	}
 
 	defer func() {
-		_ = os.RemoveAll(cmd.CacheDirectory)
+		if cmd.nodeClient != nil {
+			_ = cmd.nodeClient.Close()
+		}
 	}()
 
 	log.Log().Debug().
@@ -68,10 +91,19 @@ func (cmd *ImportCommand) Run(pctx context.Context) error {
 		Interface("privatekey", cmd.PrivatekeyFlags).
 		Interface("dev", cmd.DevFlags).
 		Str("source", cmd.Source).
+		Stringer("from_node", cmd.FromNode).
 		Interface("from_height", cmd.fromHeight).
 		Interface("to_height", cmd.toHeight).
 		Bool("do", cmd.Do).
+		Str("plan_format", cmd.PlanFormat).
 		Str("cache_directory", cmd.CacheDirectory).
+		Interface("cache_size", cmd.CacheSize).
+		Bool("keep_cache", cmd.KeepCache).
+		Str("checkpoint", cmd.Checkpoint).
+		Bool("resume", cmd.Resume).
+		Interface("progress", cmd.ProgressFlags).
+		Interface("workers", cmd.ValidateWorkersFlags).
+		Int64("remote_workers", cmd.RemoteWorkers).
 		Msg("flags")
 
 	nctx := util.ContextWithValues(pctx, map[util.ContextKey]interface{}{
@@ -99,10 +131,55 @@ func (cmd *ImportCommand) Run(pctx context.Context) error {
 		}
 	}()
 
+	if err != nil && cmd.checkpoint != nil {
+		cmd.keepCache = true
+	}
+
 	return err
 }
 
+// addCleanup registers f to be called after import; cleanups are called in
+// reverse order even if prepare fails.
+func (cmd *ImportCommand) addCleanup(f func()) {
+	cmd.cleanups = append(cmd.cleanups, f)
+}
+
+func (cmd *ImportCommand) cleanup() {
+	for i := len(cmd.cleanups) - 1; i >= 0; i-- {
+		cmd.cleanups[i]()
+	}
+}
+
 func (cmd *ImportCommand) prepare() error {
+	if err := cmd.ProgressFlags.IsValid(); err != nil {
+		return err
+	}
+
+	if err := cmd.ValidateWorkersFlags.IsValid(); err != nil {
+		return err
+	}
+
+	if cmd.RemoteWorkers < 0 {
+		return errors.Errorf("negative remote workers, %d", cmd.RemoteWorkers)
+	}
+
+	switch cmd.PlanFormat {
+	case "text", "json":
+	default:
+		return errors.Errorf("unsupported plan format, %q", cmd.PlanFormat)
+	}
+
+	switch {
+	case cmd.isFromNode() && len(cmd.Source) > 0:
+		return errors.Errorf("source and from-node can not be used together")
+	case !cmd.isFromNode() && len(cmd.Source) < 1:
+		return errors.Errorf("empty source")
+	}
+
+	if cmd.KeepCache && len(cmd.CacheDirectory) < 1 {
+		return errors.Errorf("keep-cache needs cache-directory")
+	}
+
 	cmd.fromHeight, cmd.toHeight = base.NilHeight, base.NilHeight
 
 	if h := cmd.HeightRange.From(); h != nil {
@@ -125,6 +202,12 @@ func (cmd *ImportCommand) prepare() error {
 		}
 	}
 
+	if cmd.Resume {
+		if err := cmd.prepareResume(); err != nil {
+			return err
+		}
+	}
+
 	var checkCacheDirectory func() error
 
 	switch {
@@ -136,6 +219,7 @@ func (cmd *ImportCommand) prepare() error {
 			}
 
 			cmd.CacheDirectory = i
+			cmd.tempCache = true
 
 			return nil
 		}
@@ -156,10 +240,223 @@ func (cmd *ImportCommand) prepare() error {
 		}
 	}
 
-	return checkCacheDirectory()
+	if err := checkCacheDirectory(); err != nil {
+		return err
+	}
+
+	// NOTE the cache directory given by user is not removed.
+	cmd.addCleanup(func() {
+		switch {
+		case !cmd.tempCache, cmd.KeepCache:
+			return
+		case cmd.keepCache:
+			cmd.log.Debug().Str("cache_directory", cmd.CacheDirectory).Msg("cache directory kept for resume")
+
+			return
+		}
+
+		_ = os.RemoveAll(cmd.CacheDirectory)
+	})
+
+	switch i, err := newRemoteItemCache(cmd.CacheDirectory, cmd.CacheSize.Size()); {
+	case err != nil:
+		return err
+	default:
+		cmd.cache = i
+	}
+
+	return cmd.prepareSource()
+}
+
+// prepareSource extracts the block archive into temp directory if source is
+// block archive.
+func (cmd *ImportCommand) prepareSource() error {
+	if cmd.isFromNode() {
+		d, err := os.MkdirTemp("", "mitum-import-node-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		cmd.addCleanup(func() {
+			_ = os.RemoveAll(d)
+		})
+
+		cmd.sourceDirectory = d
+
+		return nil
+	}
+
+	switch isarchive, err := isBlockArchive(cmd.Source); {
+	case err != nil:
+		return err
+	case !isarchive:
+		cmd.sourceDirectory = cmd.Source
+
+		return nil
+	}
+
+	d, err := os.MkdirTemp("", "mitum-import-archive-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cmd.addCleanup(func() {
+		_ = os.RemoveAll(d)
+	})
+
+	manifest, err := extractBlockArchive(cmd.Source, d)
+	if err != nil {
+		return err
+	}
+
+	cmd.sourceDirectory = d
+	cmd.archiveManifest = manifest
+
+	cmd.log.Debug().
+		Str("archive", cmd.Source).
+		Str("directory", d).
+		Interface("from", manifest.From).
+		Interface("to", manifest.To).
+		Msg("block archive extracted")
+
+	return nil
+}
+
+func (cmd *ImportCommand) prepareResume() error {
+	if len(cmd.Checkpoint) < 1 {
+		return errors.Errorf("resume needs checkpoint")
+	}
+
+	cp, err := loadImportCheckpoint(cmd.Checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if cp.isImported() {
+		return errors.Errorf("already imported by checkpoint; last=%d", cp.LastHeight)
+	}
+
+	switch source, err := cmd.sourceName(); {
+	case err != nil:
+		return err
+	case source != cp.Source:
+		return errors.Errorf("source does not match with checkpoint; source=%q checkpoint=%q", source, cp.Source)
+	}
+
+	switch {
+	case len(cmd.CacheDirectory) < 1:
+		cmd.CacheDirectory = cp.CacheDirectory
+		cmd.tempCache = cp.TempCacheDirectory
+	case filepath.Clean(cmd.CacheDirectory) != filepath.Clean(cp.CacheDirectory):
+		return errors.Errorf("cache directory does not match with checkpoint; cache_directory=%q checkpoint=%q",
+			cmd.CacheDirectory, cp.CacheDirectory)
+	}
+
+	cmd.fromHeight, cmd.toHeight = cp.FromHeight, cp.ToHeight
+
+	if cp.ImportedHeight >= cp.FromHeight {
+		cmd.fromHeight = cp.ImportedHeight + 1
+	}
+
+	cmd.checkpoint = cp
+
+	cmd.log.Debug().Interface("checkpoint", cp).Msg("resume from checkpoint")
+
+	return nil
+}
+
+// sourceName is the source kept in checkpoint; the absolute path of source or
+// the conn info of remote node.
+func (cmd *ImportCommand) sourceName() (string, error) {
+	if cmd.isFromNode() {
+		return cmd.FromNode.String(), nil
+	}
+
+	source, err := filepath.Abs(cmd.Source)
+
+	return source, errors.WithStack(err)
+}
+
+// prepareCheckpoint creates new checkpoint or checks the loaded checkpoint
+// with the checked heights.
+func (cmd *ImportCommand) prepareCheckpoint() error {
+	cmd.validateFrom = cmd.fromHeight
+
+	if len(cmd.Checkpoint) < 1 {
+		return nil
+	}
+
+	if cmd.checkpoint == nil {
+		source, err := cmd.sourceName()
+		if err != nil {
+			return err
+		}
+
+		cmd.checkpoint = &importCheckpoint{
+			Source:             source,
+			CacheDirectory:     cmd.CacheDirectory,
+			TempCacheDirectory: cmd.tempCache,
+			FromHeight:         cmd.fromHeight,
+			ToHeight:           cmd.toHeight,
+			LastHeight:         cmd.lastHeight,
+			ValidatedHeight:    cmd.fromHeight - 1,
+			ImportedHeight:     cmd.fromHeight - 1,
+		}
+
+		return cmd.checkpoint.save(cmd.Checkpoint)
+	}
+
+	switch cp := cmd.checkpoint; {
+	case cmd.lastHeight < cp.LastHeight:
+		return errors.Errorf("last height of source is lower than checkpoint; last=%d checkpoint=%d",
+			cmd.lastHeight, cp.LastHeight)
+	default:
+		// NOTE the blocks after the last height of checkpoint are ignored.
+		cmd.lastHeight = cp.LastHeight
+
+		if cp.ValidatedHeight >= cmd.validateFrom {
+			cmd.validateFrom = cp.ValidatedHeight + 1
+		}
+	}
+
+	return nil
+}
+
+func (cmd *ImportCommand) saveCheckpoint(validated, imported base.Height) error {
+	if cmd.checkpoint == nil {
+		return nil
+	}
+
+	if validated > cmd.checkpoint.ValidatedHeight {
+		cmd.checkpoint.ValidatedHeight = validated
+	}
+
+	if imported > cmd.checkpoint.ImportedHeight {
+		cmd.checkpoint.ImportedHeight = imported
+	}
+
+	if err := cmd.checkpoint.save(cmd.Checkpoint); err != nil {
+		return errors.WithMessage(err, "save checkpoint")
+	}
+
+	cmd.log.Debug().
+		Interface("validated_height", cmd.checkpoint.ValidatedHeight).
+		Interface("imported_height", cmd.checkpoint.ImportedHeight).
+		Msg("checkpoint saved")
+
+	return nil
 }
 
 func (cmd *ImportCommand) preImportBlocks(pctx context.Context) (context.Context, error) {
+	if cmd.isFromNode() {
+		i, err := cmd.prepareFromNode(pctx)
+		if err != nil {
+			return pctx, err
+		}
+
+		pctx = i //revive:disable-line:modifies-parameter
+	}
+
 	var design launch.NodeDesign
 	var isaacparams *isaac.Params
 	var db isaac.Database
@@ -176,7 +473,11 @@ func (cmd *ImportCommand) preImportBlocks(pctx context.Context) (context.Context
 		return pctx, err
 	}
 
-	switch i, err := newReaders(pctx, cmd.Source, nil); {
+	if cmd.archiveManifest != nil && cmd.archiveManifest.NetworkID != string(isaacparams.NetworkID()) {
+		return pctx, errors.Errorf("network id of block archive does not match")
+	}
+
+	switch i, err := newReaders(pctx, cmd.sourceDirectory, nil); {
 	case err != nil:
 		return pctx, err
 	default:
@@ -215,10 +516,37 @@ func (cmd *ImportCommand) preImportBlocks(pctx context.Context) (context.Context
 		cmd.lastHeight = i
 	}
 
+	if err := cmd.prepareCheckpoint(); err != nil {
+		return pctx, err
+	}
+
+	if !cmd.Do {
+		switch plan, err := cmd.newImportPlan(isaacparams.NetworkID(), db); {
+		case err != nil:
+			return pctx, err
+		default:
+			if err := plan.print(cmd.Out, cmd.PlanFormat); err != nil {
+				return pctx, err
+			}
+		}
+	}
+
+	if cmd.validateFrom > cmd.lastHeight {
+		cmd.log.Debug().Interface("last", cmd.lastHeight).Msg("source blocks already validated")
+
+		return pctx, nil
+	}
+
+	progress := newBlockProgress(cmd.ProgressFlags, "validate-source", cmd.validateFrom, cmd.lastHeight)
+	progress.start(pctx)
+
+	defer progress.stop()
+
 	if err := cmd.validateSourceBlocks(
-		cmd.loadItemFile(cmd.sourceReaders, fromRemotes, cmd.Do),
+		cmd.loadItemFile(cmd.sourceReaders, fromRemotes, cmd.Do, progress),
 		cmd.lastHeight,
 		isaacparams.NetworkID(),
+		progress,
 	); err != nil {
 		return pctx, err
 	}
@@ -249,34 +577,57 @@ func (cmd *ImportCommand) importBlocks(pctx context.Context) (context.Context, e
 		return pctx, e.Wrap(err)
 	}
 
-	if err := launch.ImportBlocks(
-		cmd.sourceReaders,
-		func(ctx context.Context,
-			uri url.URL,
-			compressFormat string,
-			callback func(_ io.Reader, compressFormat string) error,
-		) (known, found bool, _ error) {
-			switch found, err := cmd.readTempRemoteItemFile(uri, compressFormat, callback); {
-			case err != nil:
-				return false, false, err
-			case found:
-				return true, true, nil
-			default:
-				return fromRemotes(ctx, uri, compressFormat, callback)
-			}
-		},
-		cmd.toReaders,
-		cmd.fromHeight,
-		cmd.lastHeight,
-		encs,
-		db,
-		isaacparams,
-	); err != nil {
-		return pctx, e.Wrap(err)
+	progress := newBlockProgress(cmd.ProgressFlags, "import", cmd.fromHeight, cmd.lastHeight)
+	progress.start(pctx)
+
+	defer progress.stop()
+
+	cachedRemotes := cmd.cachedRemotes(fromRemotes, false)
+
+	remotef := func(ctx context.Context,
+		uri url.URL,
+		compressFormat string,
+		callback func(_ io.Reader, compressFormat string) error,
+	) (known, found bool, _ error) {
+		return cachedRemotes(ctx, uri, compressFormat, func(r io.Reader, compressFormat string) error {
+			return callback(progress.remoteReader(r), compressFormat)
+		})
 	}
 
-	if err := cmd.validateImported(cmd.importedReaders, cmd.lastHeight, isaacparams, db); err != nil {
-		return pctx, e.Wrap(err)
+	// NOTE without checkpoint and progress, import all blocks at once.
+	step := cmd.lastHeight - cmd.fromHeight + 1
+	if cmd.checkpoint != nil || progress != nil {
+		step = importCheckpointBlocks
+	}
+
+	for from := cmd.fromHeight; from <= cmd.lastHeight; from += step {
+		to := from + step - 1
+		if to > cmd.lastHeight {
+			to = cmd.lastHeight
+		}
+
+		if err := launch.ImportBlocks(
+			cmd.sourceReaders,
+			remotef,
+			cmd.toReaders,
+			from,
+			to,
+			encs,
+			db,
+			isaacparams,
+		); err != nil {
+			return pctx, e.Wrap(err)
+		}
+
+		if err := cmd.validateImported(cmd.importedReaders, from, to, isaacparams, db); err != nil {
+			return pctx, e.Wrap(err)
+		}
+
+		if err := cmd.saveCheckpoint(to, to); err != nil {
+			return pctx, e.Wrap(err)
+		}
+
+		progress.blocksDone(to, (to-from).Int64()+1)
 	}
 
 	return pctx, nil
@@ -295,7 +646,7 @@ func (cmd *ImportCommand) checkHeights(pctx context.Context) (base.Height, error
 		return last, err
 	}
 
-	switch fromHeight, toHeight, i, err := checkLastHeight(pctx, cmd.Source, cmd.fromHeight, cmd.toHeight); {
+	switch fromHeight, toHeight, i, err := checkLastHeight(pctx, cmd.sourceDirectory, cmd.fromHeight, cmd.toHeight); {
 	case err != nil:
 		return last, err
 	default:
@@ -314,9 +665,20 @@ func (cmd *ImportCommand) checkHeights(pctx context.Context) (base.Height, error
 	case err != nil:
 		return last, err
 	case !found:
+		if cmd.checkpoint != nil && cmd.checkpoint.ImportedHeight >= base.GenesisHeight {
+			return last, errors.Errorf(
+				"checkpoint does not match with database; last blockmap not found, imported=%d",
+				cmd.checkpoint.ImportedHeight)
+		}
 	case cmd.fromHeight < base.GenesisHeight:
 		cmd.fromHeight = i.Manifest().Height() + 1
 	case i.Manifest().Height() != cmd.fromHeight-1:
+		if cmd.checkpoint != nil {
+			return last, errors.Errorf(
+				"checkpoint does not match with database; imported=%d last=%d",
+				cmd.checkpoint.ImportedHeight, i.Manifest().Height())
+		}
+
 		return last, errors.Errorf(
 			"from height should be same with last height + 1; from=%d last=%d", cmd.fromHeight, i.Manifest().Height())
 	}
@@ -334,28 +696,39 @@ func (cmd *ImportCommand) validateSourceBlocks(
 	itemf isaac.BlockItemReadersItemFunc,
 	last base.Height,
 	networkID base.NetworkID,
+	progress *blockProgress,
 ) error {
-	// NOTE if cmd.Do is true, save the remote item files in temp directory.
+	// NOTE if cmd.Do is true, save the remote item files in cache.
 	e := util.StringError("validate source blocks")
 
-	d := last - cmd.fromHeight
+	workers := cmd.ValidateWorkersFlags.workers()
 
-	if err := util.BatchWork(
+	if err := validateBlocksBatch(
 		context.Background(),
-		d.Int64()+1,
-		333, //nolint:gomnd //...
-		func(context.Context, uint64) error {
-			return nil
+		cmd.validateFrom,
+		last,
+		workers,
+		cmd.remoteWorkers(workers),
+		cmd.ValidateWorkersFlags.batchSize(),
+		func(height base.Height) (bool, error) {
+			return hasRemoteItemFile(cmd.sourceReaders, height)
 		},
-		func(_ context.Context, i, _ uint64) error {
-			height := base.Height(int64(i) + cmd.fromHeight.Int64())
-
-			return isaacblock.IsValidBlockFromLocalFS(
+		func(_ context.Context, height base.Height) error {
+			if err := isaacblock.IsValidBlockFromLocalFS(
 				itemf,
 				height,
 				networkID,
 				nil, nil, nil,
-			)
+			); err != nil {
+				return err
+			}
+
+			progress.blockDone(height)
+
+			return nil
+		},
+		func(height base.Height) error {
+			return cmd.saveCheckpoint(height, base.NilHeight)
 		},
 	); err != nil {
 		return e.Wrap(err)
@@ -366,51 +739,81 @@ func (cmd *ImportCommand) validateSourceBlocks(
 	return nil
 }
 
+// remoteWorkers returns the number of workers for remote item files; by
+// default, half of workers.
+func (cmd *ImportCommand) remoteWorkers(workers int64) int64 {
+	switch {
+	case cmd.RemoteWorkers > 0:
+		return cmd.RemoteWorkers
+	case workers < 2: //nolint:gomnd //...
+		return 1
+	default:
+		return workers / 2 //nolint:gomnd //...
+	}
+}
+
 func (cmd *ImportCommand) loadItemFile( //revive:disable-line:flag-parameter
 	sourceReaders *isaac.BlockItemReaders,
 	fromRemotes isaac.RemotesBlockItemReadFunc,
-	saveTemp bool,
+	saveCache bool,
+	progress *blockProgress,
 ) isaac.BlockItemReadersItemFunc {
 	return isaac.BlockItemReadersItemFuncWithRemote(
 		sourceReaders,
-		fromRemotes,
+		cmd.cachedRemotes(fromRemotes, saveCache),
 		func(itemfile base.BlockItemFile, ir isaac.BlockItemReader, f isaac.BlockItemReaderCallbackFunc) error {
-			if isaac.IsInLocalBlockItemFile(itemfile.URI()) {
-				return f(ir)
-			}
-
-			if !saveTemp {
-				return f(ir)
-			}
-
-			buf := bytes.NewBuffer(nil)
-			defer func() {
-				buf.Reset()
-			}()
-
-			if _, err := ir.Reader().Tee(buf, nil); err != nil {
-				return err
-			}
-
-			if err := f(ir); err != nil {
-				return err
+			if progress != nil {
+				if _, err := ir.Reader().Tee(
+					progress.byteCounter(isaac.IsInLocalBlockItemFile(itemfile.URI())), nil); err != nil {
+					return err
+				}
 			}
 
-			return cmd.writeTempRemoteItemFile(itemfile.URI(), itemfile.CompressFormat(), buf)
+			return f(ir)
 		},
 	)(context.Background())
 }
 
+// cachedRemotes reads the remote item from cache first; with save, the remote
+// item not in cache is streamed into cache while it is read.
+func (cmd *ImportCommand) cachedRemotes( //revive:disable-line:flag-parameter
+	fromRemotes isaac.RemotesBlockItemReadFunc,
+	save bool,
+) isaac.RemotesBlockItemReadFunc {
+	return func(
+		ctx context.Context,
+		uri url.URL,
+		compressFormat string,
+		f func(_ io.Reader, compressFormat string) error,
+	) (bool, bool, error) {
+		// NOTE the node items can be read again from remote node, so they are
+		// not saved without the bounded cache size; otherwise the whole blocks of
+		// remote node are copied into cache.
+		if save && (uri.Scheme != importNodeItemScheme || cmd.CacheSize.Size() > 0) {
+			return cmd.cache.readThrough(ctx, uri, compressFormat, f, fromRemotes)
+		}
+
+		switch found, err := cmd.cache.read(uri, compressFormat, f); {
+		case err != nil:
+			return false, false, err
+		case found:
+			return true, true, nil
+		default:
+			return fromRemotes(ctx, uri, compressFormat, f)
+		}
+	}
+}
+
 func (cmd *ImportCommand) validateImported(
 	importedReaders *isaac.BlockItemReaders,
-	last base.Height,
+	from, last base.Height,
 	params *isaac.Params,
 	db isaac.Database,
 ) error {
 	e := util.StringError("validate imported")
 
 	if err := isaacblock.IsValidBlocksFromStorage(
-		importedReaders.Item, cmd.fromHeight, last, params.NetworkID(), db, nil); err != nil {
+		importedReaders.Item, from, last, params.NetworkID(), db, nil); err != nil {
 		return e.Wrap(err)
 	}
 
@@ -419,73 +822,6 @@ func (cmd *ImportCommand) validateImported(
 	return nil
 }
 
-func (cmd *ImportCommand) tempRemoteItemFileName(uri url.URL, compressFormat string) string {
-	h := valuehash.NewSHA256(util.ConcatBytesSlice(
-		[]byte((&uri).String()),
-		[]byte(compressFormat),
-	))
-
-	return filepath.Join(cmd.CacheDirectory, util.DelmSplitStrings(h.String(), "/", 32)) //nolint:gomnd //...
-}
-
-func (cmd *ImportCommand) writeTempRemoteItemFile(
-	uri url.URL,
-	compressFormat string,
-	r io.Reader,
-) error {
-	p := cmd.tempRemoteItemFileName(uri, compressFormat)
-
-	switch fi, err := os.Stat(p); {
-	case os.IsNotExist(err):
-	case err != nil:
-		return errors.WithStack(err)
-	case fi.IsDir():
-		return errors.Errorf("directory")
-	default:
-		return nil
-	}
-
-	switch err := os.MkdirAll(filepath.Dir(p), 0o700); {
-	case os.IsExist(err):
-	case err != nil:
-		return errors.WithStack(err)
-	}
-
-	switch f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600); {
-	case err != nil:
-		return errors.WithStack(err)
-	default:
-		defer func() {
-			_ = f.Close()
-		}()
-
-		if _, err := io.Copy(f, r); err != nil {
-			return errors.WithStack(err)
-		}
-	}
-
-	return nil
-}
-
-func (cmd *ImportCommand) readTempRemoteItemFile(
-	uri url.URL,
-	compressFormat string,
-	f func(io.Reader, string) error,
-) (bool, error) {
-	switch i, err := os.Open(cmd.tempRemoteItemFileName(uri, compressFormat)); {
-	case os.IsNotExist(err):
-		return false, nil
-	case err != nil:
-		return false, errors.WithStack(err)
-	default:
-		defer func() {
-			_ = i.Close()
-		}()
-
-		return true, f(i, compressFormat)
-	}
-}
-
 func checkLastHeight(pctx context.Context, root string, fromHeight, toHeight base.Height) (
 	base.Height,
 	base.Height,
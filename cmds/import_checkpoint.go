@@ -0,0 +1,70 @@
+package cmds
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/localtime"
+	"github.com/pkg/errors"
+)
+
+// importCheckpointBlocks is the number of blocks imported between checkpoints
+// or progress reports.
+var importCheckpointBlocks base.Height = 3333 //nolint:gomnd //...
+
+// importCheckpoint keeps the progress of import; with `--resume`, import
+// continues from the last imported and validated height.
+type importCheckpoint struct {
+	UpdatedAt      time.Time `json:"updated_at"`
+	Source         string    `json:"source"`
+	CacheDirectory string    `json:"cache_directory"`
+	// TempCacheDirectory is true when cache directory was created by import;
+	// it is removed after resumed import.
+	TempCacheDirectory bool        `json:"temp_cache_directory,omitempty"`
+	FromHeight         base.Height `json:"from_height"`
+	ToHeight           base.Height `json:"to_height"`
+	LastHeight         base.Height `json:"last_height"`
+	ValidatedHeight    base.Height `json:"validated_height"`
+	ImportedHeight     base.Height `json:"imported_height"`
+}
+
+func loadImportCheckpoint(f string) (*importCheckpoint, error) {
+	b, err := os.ReadFile(filepath.Clean(f))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var cp importCheckpoint
+
+	if err := util.UnmarshalJSON(b, &cp); err != nil {
+		return nil, errors.WithMessagef(err, "load checkpoint, %q", f)
+	}
+
+	return &cp, nil
+}
+
+// save writes the checkpoint thru temp file, so the previous checkpoint is
+// kept when interrupted while writing.
+func (cp *importCheckpoint) save(f string) error {
+	cp.UpdatedAt = localtime.Now().UTC()
+
+	b, err := util.MarshalJSONIndent(cp)
+	if err != nil {
+		return err
+	}
+
+	temp := f + ".tmp"
+
+	if err := os.WriteFile(temp, b, 0o600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.Rename(temp, f))
+}
+
+func (cp *importCheckpoint) isImported() bool {
+	return cp.ImportedHeight >= cp.LastHeight
+}
@@ -0,0 +1,259 @@
+package cmds
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	isaacnetwork "github.com/imfact-labs/mitum2/isaac/network"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/pkg/errors"
+)
+
+// importNodeItemScheme is the scheme of item file uri, which is read from the
+// remote node of `--from-node`; the uri looks like
+// `mitum-node://<node address>/<height>/<item type>`.
+var importNodeItemScheme = "mitum-node"
+
+func (cmd *ImportCommand) isFromNode() bool {
+	return cmd.FromNode.ConnInfo().IsValid(nil) == nil
+}
+
+// prepareFromNode saves the block item files of remote node into the source
+// directory. The local item files of remote node are replaced by the node item
+// uri, so the item files are not staged in local, but read from remote node
+// when they are needed.
+func (cmd *ImportCommand) prepareFromNode(pctx context.Context) (context.Context, error) {
+	e := util.StringError("prepare from node")
+
+	var encs *encoder.Encoders
+	var local base.LocalNode
+	var isaacparams *isaac.Params
+	var db isaac.Database
+	var fromRemotes isaac.RemotesBlockItemReadFunc
+
+	if err := util.LoadFromContextOK(pctx,
+		launch.EncodersContextKey, &encs,
+		launch.LocalContextKey, &local,
+		launch.ISAACParamsContextKey, &isaacparams,
+		launch.CenterDatabaseContextKey, &db,
+		launch.RemotesBlockItemReaderFuncContextKey, &fromRemotes,
+	); err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	connectionPool, err := launch.NewConnectionPool(
+		1<<9, //nolint:gomnd //...
+		isaacparams.NetworkID(),
+		nil,
+	)
+	if err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	cmd.nodeClient = isaacnetwork.NewBaseClient(
+		encs, encs.JSON(),
+		connectionPool.Dial,
+		connectionPool.CloseAll,
+	)
+
+	from, to, err := cmd.fromNodeHeights(pctx, db)
+	if err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	// NOTE only the item files of heights to import are staged, so from height
+	// is fixed.
+	if from > base.GenesisHeight {
+		cmd.fromHeight = from
+	}
+
+	cmd.log.Debug().
+		Stringer("from_node", cmd.FromNode).
+		Interface("from", from).
+		Interface("to", to).
+		Msg("block item files of remote node will be saved")
+
+	if err := util.RunJobWorker(pctx, cmd.ValidateWorkersFlags.workers(), (to-from).Int64()+1,
+		func(ctx context.Context, i, _ uint64) error {
+			height := from + base.Height(int64(i))
+
+			if err := cmd.saveFromNodeItemFiles(
+				ctx, height, local.Privatekey(), isaacparams.NetworkID(), encs.JSON()); err != nil {
+				return errors.WithMessagef(err, "height %d", height)
+			}
+
+			return nil
+		},
+	); err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	return util.ContextWithValues(pctx, map[util.ContextKey]interface{}{
+		launch.RemotesBlockItemReaderFuncContextKey: cmd.fromNodeRemotes(fromRemotes),
+	}), nil
+}
+
+// fromNodeHeights returns the heights to import from remote node; without from
+// height, it starts from the next of the last local block.
+func (cmd *ImportCommand) fromNodeHeights(
+	pctx context.Context, db isaac.Database,
+) (from base.Height, to base.Height, _ error) {
+	from, to = cmd.fromHeight, cmd.toHeight
+
+	if from < base.GenesisHeight {
+		switch bm, found, err := db.LastBlockMap(); {
+		case err != nil:
+			return from, to, err
+		case found:
+			from = bm.Manifest().Height() + 1
+		default:
+			from = base.GenesisHeight
+		}
+	}
+
+	if to < base.GenesisHeight {
+		ctx, cancel := context.WithTimeout(pctx, isaac.DefaultTimeoutRequest)
+		defer cancel()
+
+		switch bm, found, err := cmd.nodeClient.LastBlockMap(ctx, cmd.FromNode.ConnInfo(), nil); {
+		case err != nil:
+			return from, to, err
+		case !found:
+			return from, to, util.ErrNotFound.Errorf("last blockmap of remote node")
+		default:
+			to = bm.Manifest().Height()
+		}
+	}
+
+	if from > to {
+		return from, to, errors.Errorf("from height is higher than last of remote node; from=%d last=%d", from, to)
+	}
+
+	return from, to, nil
+}
+
+func (cmd *ImportCommand) saveFromNodeItemFiles(
+	pctx context.Context,
+	height base.Height,
+	priv base.Privatekey,
+	networkID base.NetworkID,
+	enc encoder.Encoder,
+) error {
+	ctx, cancel := context.WithTimeout(pctx, isaac.DefaultTimeoutRequest*2) //nolint:gomnd //...
+	defer cancel()
+
+	var bfiles base.BlockItemFiles
+
+	switch found, err := cmd.nodeClient.BlockItemFiles(
+		ctx,
+		cmd.FromNode.ConnInfo(),
+		height,
+		priv,
+		networkID,
+		func(r io.Reader) error {
+			return encoder.DecodeReader(enc, r, &bfiles)
+		},
+	); {
+	case err != nil:
+		return err
+	case !found:
+		return util.ErrNotFound.Errorf("block item files")
+	}
+
+	maker := isaac.NewBlockItemFilesMaker(enc)
+
+	for t, bf := range bfiles.Items() {
+		i := bf
+
+		if isaac.IsInLocalBlockItemFile(bf.URI()) {
+			i = isaac.NewBlockItemFile(
+				importNodeItemURI(cmd.FromNode.ConnInfo().Addr().String(), height, t), bf.CompressFormat())
+		}
+
+		if _, err := maker.SetItem(t, i); err != nil {
+			return err
+		}
+	}
+
+	b, err := maker.Bytes()
+	if err != nil {
+		return err
+	}
+
+	p := isaac.BlockItemFilesPath(cmd.sourceDirectory, height)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(p, b, 0o600))
+}
+
+// fromNodeRemotes reads the node item uri from remote node; the other uris are
+// read by fromRemotes.
+func (cmd *ImportCommand) fromNodeRemotes(fromRemotes isaac.RemotesBlockItemReadFunc) isaac.RemotesBlockItemReadFunc {
+	return func(
+		ctx context.Context,
+		uri url.URL,
+		compressFormat string,
+		callback func(io.Reader, string) error,
+	) (bool, bool, error) {
+		if uri.Scheme != importNodeItemScheme {
+			return fromRemotes(ctx, uri, compressFormat, callback)
+		}
+
+		height, t, err := parseImportNodeItemURI(uri)
+		if err != nil {
+			return true, false, err
+		}
+
+		found, err := cmd.nodeClient.BlockItem(
+			ctx,
+			cmd.FromNode.ConnInfo(),
+			height,
+			t,
+			func(r io.Reader, _ url.URL, _ string) error {
+				if r == nil {
+					return util.ErrNotFound.Errorf("block item file in remote node, %q", t)
+				}
+
+				return callback(r, compressFormat)
+			},
+		)
+
+		return true, found, err
+	}
+}
+
+// importNodeItemURI makes the node item uri; the host is needed because the
+// item file of non-local scheme without host is not valid.
+func importNodeItemURI(host string, height base.Height, t base.BlockItemType) url.URL {
+	return url.URL{
+		Scheme: importNodeItemScheme,
+		Host:   host,
+		Path:   "/" + height.String() + "/" + t.String(),
+	}
+}
+
+func parseImportNodeItemURI(uri url.URL) (base.Height, base.BlockItemType, error) {
+	l := strings.SplitN(strings.TrimPrefix(uri.Path, "/"), "/", 2)
+	if len(l) != 2 { //nolint:gomnd //...
+		return base.NilHeight, "", errors.Errorf("invalid node item uri, %q", uri.String())
+	}
+
+	i, err := strconv.ParseInt(l[0], 10, 64)
+	if err != nil {
+		return base.NilHeight, "", errors.Errorf("invalid height of node item uri, %q", uri.String())
+	}
+
+	return base.Height(i), base.BlockItemType(l[1]), nil
+}
@@ -0,0 +1,76 @@
+package cmds
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	jsonenc "github.com/imfact-labs/mitum2/util/encoder/json"
+)
+
+func TestParseImportNodeItemURI(t *testing.T) {
+	cases := []struct {
+		name   string
+		uri    url.URL
+		height base.Height
+		item   base.BlockItemType
+		err    bool
+	}{
+		{
+			name:   "ok",
+			uri:    importNodeItemURI("127.0.0.1:4320", 33, base.BlockItemStates),
+			height: 33,
+			item:   base.BlockItemStates,
+		},
+		{
+			name:   "genesis",
+			uri:    importNodeItemURI("127.0.0.1:4320", base.GenesisHeight, base.BlockItemMap),
+			height: base.GenesisHeight,
+			item:   base.BlockItemMap,
+		},
+		{name: "empty", uri: url.URL{Scheme: importNodeItemScheme}, err: true},
+		{name: "no item", uri: url.URL{Scheme: importNodeItemScheme, Path: "/33"}, err: true},
+		{name: "wrong height", uri: url.URL{Scheme: importNodeItemScheme, Path: "/a/map"}, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			height, item, err := parseImportNodeItemURI(c.uri)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			case height != c.height:
+				t.Errorf("height: expected %d, got %d", c.height, height)
+			case item != c.item:
+				t.Errorf("item: expected %q, got %q", c.item, item)
+			}
+		})
+	}
+}
+
+func TestImportNodeItemURIInItemFiles(t *testing.T) {
+	maker := isaac.NewBlockItemFilesMaker(jsonenc.NewEncoder())
+
+	for _, item := range []base.BlockItemType{base.BlockItemMap, base.BlockItemStates} {
+		uri := importNodeItemURI("127.0.0.1:4320", 33, item)
+
+		if _, err := maker.SetItem(item, isaac.NewBlockItemFile(uri, "gz")); err != nil {
+			t.Fatalf("item %q: %+v", item, err)
+		}
+	}
+
+	if _, err := maker.SetItem(base.BlockItemMap,
+		isaac.NewBlockItemFile(url.URL{Scheme: importNodeItemScheme, Path: "/33/map"}, "gz")); err == nil {
+		t.Error("expected error without host")
+	}
+
+	if _, err := maker.Bytes(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package cmds
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/localtime"
+	"github.com/pkg/errors"
+)
+
+const (
+	importPlanMatched    = "matched"
+	importPlanMismatched = "mismatched"
+	importPlanUnknown    = "unknown"
+	importPlanNotSource  = "not-in-source"
+)
+
+// importPlan is the result of dry run of import; it shows what will be
+// imported with `--do`.
+type importPlan struct {
+	CreatedAt        time.Time                   `json:"created_at"`
+	Items            map[string]*importPlanItems `json:"items"`
+	RemoteHosts      map[string]int64            `json:"remote_hosts"`
+	Source           string                      `json:"source"`
+	NetworkID        string                      `json:"network_id"`
+	Suffrage         string                      `json:"suffrage"`
+	PreviousBlockMap string                      `json:"previous_blockmap,omitempty"`
+	Mismatches       []string                    `json:"mismatches"`
+	From             base.Height                 `json:"from"`
+	To               base.Height                 `json:"to"`
+	Blocks           int64                       `json:"blocks"`
+	LocalFiles       int64                       `json:"local_files"`
+	LocalBytes       int64                       `json:"local_bytes"`
+	RemoteFiles      int64                       `json:"remote_files"`
+}
+
+type importPlanItems struct {
+	LocalFiles  int64 `json:"local_files"`
+	LocalBytes  int64 `json:"local_bytes"`
+	RemoteFiles int64 `json:"remote_files"`
+}
+
+func (cmd *ImportCommand) newImportPlan(networkID base.NetworkID, db isaac.Database) (*importPlan, error) {
+	e := util.StringError("import plan")
+
+	source, err := cmd.sourceName()
+	if err != nil {
+		return nil, e.Wrap(err)
+	}
+
+	plan := &importPlan{
+		CreatedAt:   localtime.Now().UTC(),
+		Source:      source,
+		Items:       map[string]*importPlanItems{},
+		RemoteHosts: map[string]int64{},
+		Mismatches:  []string{},
+		From:        cmd.fromHeight,
+		To:          cmd.lastHeight,
+		Blocks:      int64(cmd.lastHeight - cmd.fromHeight + 1),
+	}
+
+	if err := cmd.checkImportPlanNetwork(plan, networkID, db); err != nil {
+		return nil, e.Wrap(err)
+	}
+
+	for height := cmd.fromHeight; height <= cmd.lastHeight; height++ {
+		if err := cmd.addImportPlanItems(plan, height); err != nil {
+			return nil, e.Wrap(err)
+		}
+	}
+
+	return plan, nil
+}
+
+func (cmd *ImportCommand) checkImportPlanNetwork(plan *importPlan, networkID base.NetworkID, db isaac.Database) error {
+	switch m, found, err := isaac.BlockItemReadersDecode[base.BlockMap](
+		cmd.sourceReaders.Item, cmd.fromHeight, base.BlockItemMap, nil); {
+	case err != nil:
+		return err
+	case !found:
+		return util.ErrNotFound.Errorf("blockmap of from height, %d", cmd.fromHeight)
+	default:
+		plan.NetworkID = importPlanMatched
+
+		if err := m.IsValid(networkID); err != nil {
+			plan.NetworkID = importPlanMismatched
+			plan.Mismatches = append(plan.Mismatches,
+				fmt.Sprintf("blockmap of from height, %d is not signed with network id of design: %v",
+					cmd.fromHeight, err))
+		}
+	}
+
+	plan.Suffrage = importPlanUnknown
+
+	if cmd.fromHeight <= base.GenesisHeight {
+		return nil
+	}
+
+	var sourcePrev base.BlockMap
+
+	switch m, found, err := isaac.BlockItemReadersDecode[base.BlockMap](
+		cmd.sourceReaders.Item, cmd.fromHeight-1, base.BlockItemMap, nil); {
+	case err != nil:
+		return err
+	case !found:
+		plan.PreviousBlockMap = importPlanNotSource
+	default:
+		sourcePrev = m
+		plan.PreviousBlockMap = importPlanMatched
+
+		if err := base.IsEqualBlockMap(cmd.prevblockmap, m); err != nil {
+			plan.PreviousBlockMap = importPlanMismatched
+			plan.Mismatches = append(plan.Mismatches,
+				fmt.Sprintf("blockmap of previous height, %d does not match with database: %v",
+					cmd.fromHeight-1, err))
+		}
+	}
+
+	if sourcePrev == nil {
+		return nil
+	}
+
+	switch st, found, err := db.State(isaac.SuffrageStateKey); {
+	case err != nil:
+		return err
+	case !found:
+	case st.Hash().Equal(sourcePrev.Manifest().Suffrage()):
+		plan.Suffrage = importPlanMatched
+	default:
+		plan.Suffrage = importPlanMismatched
+		plan.Mismatches = append(plan.Mismatches,
+			fmt.Sprintf("suffrage of previous height, %d does not match with database; source=%s local=%s",
+				cmd.fromHeight-1, sourcePrev.Manifest().Suffrage(), st.Hash()))
+	}
+
+	return nil
+}
+
+func (cmd *ImportCommand) addImportPlanItems(plan *importPlan, height base.Height) error {
+	bfiles, found, err := cmd.sourceReaders.ItemFiles(height)
+
+	switch {
+	case err != nil:
+		return err
+	case !found:
+		plan.Mismatches = append(plan.Mismatches, fmt.Sprintf("block item files not found in source, %d", height))
+
+		return nil
+	}
+
+	for t, bf := range bfiles.Items() {
+		items, ok := plan.Items[t.String()]
+		if !ok {
+			items = &importPlanItems{}
+			plan.Items[t.String()] = items
+		}
+
+		uri := bf.URI()
+
+		if !isaac.IsInLocalBlockItemFile(uri) {
+			items.RemoteFiles++
+			plan.RemoteFiles++
+			plan.RemoteHosts[(&url.URL{Scheme: uri.Scheme, Host: uri.Host}).String()]++
+
+			continue
+		}
+
+		switch fi, err := os.Stat(localItemFilePath(cmd.sourceDirectory, height, uri)); {
+		case os.IsNotExist(err):
+			plan.Mismatches = append(plan.Mismatches,
+				fmt.Sprintf("local item file not found in source; height=%d item=%q", height, t))
+		case err != nil:
+			return errors.WithStack(err)
+		default:
+			items.LocalFiles++
+			items.LocalBytes += fi.Size()
+			plan.LocalFiles++
+			plan.LocalBytes += fi.Size()
+		}
+	}
+
+	return nil
+}
+
+// localItemFilePath returns the path of local item file; the path of `file`
+// scheme is absolute and the one of local scheme is under the height
+// directory.
+func localItemFilePath(root string, height base.Height, uri url.URL) string {
+	if uri.Scheme == "file" {
+		return filepath.Clean(uri.Path)
+	}
+
+	return filepath.Join(root, isaac.BlockHeightDirectory(height), uri.Path)
+}
+
+func (plan *importPlan) print(w io.Writer, format string) error {
+	if format == "json" {
+		b, err := util.MarshalJSONIndent(plan)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(b))
+
+		return errors.WithStack(err)
+	}
+
+	_, _ = fmt.Fprintf(w, "source: %s\n", plan.Source)
+	_, _ = fmt.Fprintf(w, "heights: %d-%d (%d blocks)\n", plan.From, plan.To, plan.Blocks)
+	_, _ = fmt.Fprintf(w, "network id: %s\n", plan.NetworkID)
+	_, _ = fmt.Fprintf(w, "suffrage: %s\n", plan.Suffrage)
+
+	if len(plan.PreviousBlockMap) > 0 {
+		_, _ = fmt.Fprintf(w, "previous blockmap: %s\n", plan.PreviousBlockMap)
+	}
+
+	_, _ = fmt.Fprintf(w, "local files: %d (%s)\n", plan.LocalFiles, humanBytes(uint64(plan.LocalBytes)))
+	_, _ = fmt.Fprintf(w, "remote files: %d\n", plan.RemoteFiles)
+
+	_, _ = fmt.Fprintln(w, "items:")
+
+	for _, t := range sortedStringKeys(plan.Items) {
+		i := plan.Items[t]
+
+		_, _ = fmt.Fprintf(w, "  - %s\tlocal=%d (%s)\tremote=%d\n",
+			t, i.LocalFiles, humanBytes(uint64(i.LocalBytes)), i.RemoteFiles)
+	}
+
+	if len(plan.RemoteHosts) > 0 {
+		_, _ = fmt.Fprintln(w, "remote hosts:")
+
+		for _, h := range sortedStringKeys(plan.RemoteHosts) {
+			_, _ = fmt.Fprintf(w, "  - %s\t%d\n", h, plan.RemoteHosts[h])
+		}
+	}
+
+	if len(plan.Mismatches) > 0 {
+		_, _ = fmt.Fprintln(w, "mismatches:")
+
+		for i := range plan.Mismatches {
+			_, _ = fmt.Fprintf(w, "  - %s\n", plan.Mismatches[i])
+		}
+	}
+
+	return nil
+}
+
+func sortedStringKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
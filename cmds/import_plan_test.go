@@ -0,0 +1,38 @@
+package cmds
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/imfact-labs/mitum2/isaac"
+)
+
+func TestLocalItemFilePath(t *testing.T) {
+	heightdir := isaac.BlockHeightDirectory(3)
+
+	cases := []struct {
+		name     string
+		uri      url.URL
+		expected string
+	}{
+		{
+			name:     "local",
+			uri:      isaac.NewLocalFSBlockItemFile("map.json.gz", "gz").URI(),
+			expected: filepath.Join("/source", heightdir, "map.json.gz"),
+		},
+		{
+			name:     "file",
+			uri:      isaac.NewFileBlockItemFile("/a/b/map.json.gz", "gz").URI(),
+			expected: "/a/b/map.json.gz",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if p := localItemFilePath("/source", 3, c.uri); p != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, p)
+			}
+		})
+	}
+}
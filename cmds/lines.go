@@ -0,0 +1,40 @@
+package cmds
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func readLinesFile(f string) ([]string, error) {
+	r, err := os.Open(filepath.Clean(f))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	return readLines(r)
+}
+
+// readLines reads the trimmed lines; the empty lines and the lines starting
+// with `#` are ignored.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	sc := bufio.NewScanner(r)
+
+	for sc.Scan() {
+		if i := strings.TrimSpace(sc.Text()); len(i) > 0 && !strings.HasPrefix(i, "#") {
+			lines = append(lines, i)
+		}
+	}
+
+	return lines, errors.WithStack(sc.Err())
+}
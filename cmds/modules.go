@@ -0,0 +1,294 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/imfact-labs/currency-model/app/modulekit"
+	"github.com/imfact-labs/imfact-model/runtime/spec"
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/pkg/errors"
+)
+
+// HandlerPathNodeModules serves the composed modules of the node thru the
+// digest api.
+var HandlerPathNodeModules = "/node/modules"
+
+type ModulesCommand struct { //nolint:govet //...
+	List ModulesListCommand `cmd:"" name:"list" help:"list composed modules"`
+	Show ModulesShowCommand `cmd:"" name:"show" help:"show composed module"`
+}
+
+type baseModulesCommand struct {
+	//revive:disable:line-length-limit
+	BaseCommand
+	Format  string   `name:"format" help:"output format, {table, json}" default:"table"`
+	Design  string   `name:"design" help:"node design file; the modules composed by design are listed" type:"existingfile" placeholder:"file"`
+	Modules []string `name:"modules" sep:"," help:"model modules to compose; overrides 'modules' of design" placeholder:"module"`
+	//revive:enable:line-length-limit
+}
+
+func (cmd *baseModulesCommand) prepare(pctx context.Context) (*modulekit.Registry, error) {
+	switch cmd.Format {
+	case "table", "json":
+	default:
+		return nil, errors.Errorf("unsupported format, %q", cmd.Format)
+	}
+
+	if _, err := cmd.BaseCommand.prepare(pctx); err != nil {
+		return nil, err
+	}
+
+	ids := cmd.Modules
+
+	if len(ids) < 1 && len(cmd.Design) > 0 {
+		i, err := loadModulesFromDesignFile(cmd.Design)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = i
+	}
+
+	// NOTE same with run; without design and modules, all the available
+	// modules are composed.
+	if _, err := composeModules(ids); err != nil {
+		return nil, err
+	}
+
+	return spec.LoadModuleRegistry()
+}
+
+type ModulesListCommand struct { //nolint:govet //...
+	baseModulesCommand
+}
+
+func (cmd *ModulesListCommand) Run(pctx context.Context) error {
+	registry, err := cmd.prepare(pctx)
+	if err != nil {
+		return err
+	}
+
+	entries := registry.Entries()
+
+	infos := make([]moduleInfo, len(entries))
+	for i := range entries {
+		infos[i] = newModuleInfo(entries[i])
+	}
+
+	if cmd.Format == "json" {
+		return cmd.printJSON(infos)
+	}
+
+	w := tabwriter.NewWriter(cmd.Out, 0, 0, 2, ' ', 0) //nolint:gomnd //...
+
+	_, _ = fmt.Fprintln(w, "ID\tHINTERS\tSUPPORTED FACTS\tOPERATION PROCESSORS\tAPI HANDLERS")
+
+	for i := range infos {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n",
+			infos[i].ID,
+			len(infos[i].Hinters),
+			len(infos[i].SupportedFacts),
+			len(infos[i].OperationProcessors),
+			len(infos[i].APIHandlers),
+		)
+	}
+
+	return errors.WithStack(w.Flush())
+}
+
+type ModulesShowCommand struct { //nolint:govet //...
+	baseModulesCommand
+	ID string `arg:"" name:"module id" help:"module id"`
+}
+
+func (cmd *ModulesShowCommand) Run(pctx context.Context) error {
+	registry, err := cmd.prepare(pctx)
+	if err != nil {
+		return err
+	}
+
+	entry, found := registry.Module(strings.TrimSpace(cmd.ID))
+	if !found {
+		return util.ErrNotFound.Errorf("module, %q; composed=%q", cmd.ID, registry.ModuleIDs())
+	}
+
+	info := newModuleInfo(entry)
+
+	if cmd.Format == "json" {
+		return cmd.printJSON(info)
+	}
+
+	w := tabwriter.NewWriter(cmd.Out, 0, 0, 2, ' ', 0) //nolint:gomnd //...
+
+	section := func(name string, n int) {
+		_, _ = fmt.Fprintf(w, "\n## %s (%d)\n", name, n)
+	}
+
+	_, _ = fmt.Fprintf(w, "id: %s\n", info.ID)
+
+	section("hinters", len(info.Hinters))
+	for i := range info.Hinters {
+		_, _ = fmt.Fprintf(w, "  - %s\n", info.Hinters[i])
+	}
+
+	section("supported proposal facts", len(info.SupportedFacts))
+	for i := range info.SupportedFacts {
+		_, _ = fmt.Fprintf(w, "  - %s\n", info.SupportedFacts[i])
+	}
+
+	section("operation processors", len(info.OperationProcessors))
+	for i := range info.OperationProcessors {
+		p := info.OperationProcessors[i]
+		_, _ = fmt.Fprintf(w, "  - %s\tA=%v\tB=%v\n", p.Name, p.SupportsA, p.SupportsB)
+	}
+
+	section("api routes", len(info.APIRoutes))
+	for i := range info.APIRoutes {
+		r := info.APIRoutes[i]
+		_, _ = fmt.Fprintf(w, "  - %s\t%s\n", strings.Join(r.Methods, ","), r.Path)
+	}
+
+	section("api handlers", len(info.APIHandlers))
+	for i := range info.APIHandlers {
+		_, _ = fmt.Fprintf(w, "  - %s\n", info.APIHandlers[i])
+	}
+
+	section("cli commands", len(info.CLICommands))
+	for i := range info.CLICommands {
+		c := info.CLICommands[i]
+		_, _ = fmt.Fprintf(w, "  - %s\t%s\n", c.Key, c.Description)
+	}
+
+	return errors.WithStack(w.Flush())
+}
+
+func (cmd *baseModulesCommand) printJSON(v interface{}) error {
+	b, err := util.MarshalJSONIndent(v)
+	if err != nil {
+		return err
+	}
+
+	cmd.print("%s", string(b))
+
+	return nil
+}
+
+type moduleInfo struct {
+	ID                  string                         `json:"id"`
+	Hinters             []string                       `json:"hinters"`
+	SupportedFacts      []string                       `json:"supported_facts"`
+	OperationProcessors []moduleOperationProcessorInfo `json:"operation_processors"`
+	APIRoutes           []moduleAPIRouteInfo           `json:"api_routes"`
+	APIHandlers         []string                       `json:"api_handlers"`
+	CLICommands         []moduleCLICommandInfo         `json:"cli_commands"`
+}
+
+type moduleAPIRouteInfo struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+}
+
+type moduleCLICommandInfo struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+type moduleOperationProcessorInfo struct {
+	Name      string `json:"name"`
+	SupportsA bool   `json:"supports_a"`
+	SupportsB bool   `json:"supports_b"`
+}
+
+func newModuleInfo(entry modulekit.ModuleEntry) moduleInfo {
+	info := moduleInfo{
+		ID:                  entry.ID,
+		Hinters:             hintStrings(entry.Hinters),
+		SupportedFacts:      hintStrings(entry.SupportedFacts),
+		OperationProcessors: make([]moduleOperationProcessorInfo, len(entry.OperationProcessors)),
+		APIRoutes:           make([]moduleAPIRouteInfo, len(entry.APIRoutes)),
+		APIHandlers:         make([]string, len(entry.APIHandlers)),
+		CLICommands:         make([]moduleCLICommandInfo, len(entry.CLICommands)),
+	}
+
+	for i := range entry.OperationProcessors {
+		p := entry.OperationProcessors[i]
+
+		info.OperationProcessors[i] = moduleOperationProcessorInfo{
+			Name:      p.Name.String(),
+			SupportsA: p.SupportsA,
+			SupportsB: p.SupportsB,
+		}
+	}
+
+	for i := range entry.APIRoutes {
+		info.APIRoutes[i] = moduleAPIRouteInfo{
+			Path:    entry.APIRoutes[i].Path,
+			Methods: entry.APIRoutes[i].Methods,
+		}
+	}
+
+	for i := range entry.APIHandlers {
+		info.APIHandlers[i] = entry.APIHandlers[i].Key
+	}
+
+	for i := range entry.CLICommands {
+		info.CLICommands[i] = moduleCLICommandInfo{
+			Key:         entry.CLICommands[i].Key,
+			Description: entry.CLICommands[i].Description,
+		}
+	}
+
+	return info
+}
+
+func hintStrings(details []encoder.DecodeDetail) []string {
+	hs := make([]string, len(details))
+
+	for i := range details {
+		hs[i] = details[i].Hint.String()
+	}
+
+	return hs
+}
+
+type nodeModulesInfo struct {
+	Address string       `json:"address"`
+	Version string       `json:"version"`
+	Modules []moduleInfo `json:"modules"`
+}
+
+func handleNodeModules(local base.LocalNode, version util.Version) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+
+		entries := mustBuildModuleRegistry().Entries()
+
+		info := nodeModulesInfo{
+			Address: local.Address().String(),
+			Version: version.String(),
+			Modules: make([]moduleInfo, len(entries)),
+		}
+
+		for i := range entries {
+			info.Modules[i] = newModuleInfo(entries[i])
+		}
+
+		b, err := util.MarshalJSON(info)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	}
+}
@@ -1,10 +1,73 @@
 package cmds
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/imfact-labs/currency-model/app/modulekit"
 	"github.com/imfact-labs/imfact-model/runtime/spec"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 )
 
 func mustBuildModuleRegistry() *modulekit.Registry {
 	return spec.MustBuildModuleRegistry()
 }
+
+// loadModules selects the composed modules. The modules flag takes precedence
+// over the `modules` section of the node design.
+func loadModules(flag launch.DesignFlag, ids []string) ([]string, error) {
+	if len(ids) < 1 {
+		switch i, err := loadModulesFromDesign(flag); {
+		case err != nil:
+			return nil, err
+		default:
+			ids = i
+		}
+	}
+
+	return composeModules(ids)
+}
+
+// composeModules selects the modules by id; empty ids selects all the
+// available modules.
+func composeModules(ids []string) ([]string, error) {
+	if err := spec.SetModules(ids); err != nil {
+		return nil, errors.WithMessage(err, "select modules")
+	}
+
+	return mustBuildModuleRegistry().ModuleIDs(), nil
+}
+
+func loadModulesFromDesign(flag launch.DesignFlag) ([]string, error) {
+	if flag.Scheme() != "file" {
+		// NOTE only the local design file is supported; use the modules flag.
+		return nil, nil
+	}
+
+	return loadModulesFromDesignFile(flag.URL().Path)
+}
+
+func loadModulesFromDesignFile(f string) ([]string, error) {
+	b, err := os.ReadFile(filepath.Clean(f))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	nb, err := util.ReplaceEnvVariables(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var m struct {
+		Modules []string `yaml:"modules"`
+	}
+
+	if err := yaml.Unmarshal(nb, &m); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return m.Modules, nil
+}
@@ -7,6 +7,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/alecthomas/kong"
 	"github.com/imfact-labs/mitum2/base"
@@ -18,16 +21,25 @@ import (
 	"github.com/pkg/errors"
 )
 
+var defaultBlockItemDownloadWorkers int64 = 8 //nolint:gomnd //...
+
 type NetworkClientBlockItemFilesCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
 	BaseNetworkClientCommand
-	Privatekey         string            `arg:"" name:"privatekey" help:"privatekey string"`
-	Height             launch.HeightFlag `arg:""`
-	OutputDirectory    string            `arg:"" name:"output directory" default:""`
-	DownloadRemoteItem bool              `name:"download-remote-item"`
-	DownloadAllItems   bool              `name:"download-all-items"`
+	Privatekey         string          `arg:"" name:"privatekey" help:"privatekey string"`
+	HeightRange        HeightRangeFlag `arg:"" name:"range" help:"<height>, <from>-<to> or <from>-; <from>- is until the last height of remote"`
+	OutputDirectory    string          `arg:"" name:"output directory" default:""`
+	DownloadRemoteItem bool            `name:"download-remote-item" negatable:"" help:"download the remote item files; without it, remote items are skipped" default:"true"`
+	DownloadAllItems   bool            `name:"download-all-items" negatable:"" help:"download all items with output directory; without it, only block item files are saved" default:"true"`
+	Workers            int64           `name:"workers" help:"number of concurrent downloads" default:"8" placeholder:"count"`
 	priv               base.Privatekey
 	remoteItemf        isaac.RemotesBlockItemReadFunc
 	decompressf        util.DecompressReaderFunc
+	outl               sync.Mutex
+	skipped            atomic.Int64
+	fromHeight         base.Height
+	toHeight           base.Height
+	//revive:enable:line-length-limit
 }
 
 func (cmd *NetworkClientBlockItemFilesCommand) Run(
@@ -41,10 +53,18 @@ func (cmd *NetworkClientBlockItemFilesCommand) Run(
 		return err
 	}
 
-	if err := cmd.download(kctx, pctx); err != nil {
+	switch err := cmd.download(pctx); {
+	case errors.Is(err, util.ErrNotFound):
+		kctx.Errorf(err.Error())
+		kctx.Exit(2)
+	case err != nil:
 		return err
 	}
 
+	if n := cmd.skipped.Load(); n > 0 {
+		cmd.Log.Warn().Int64("skipped", n).Msg("remote items skipped; not complete blocks")
+	}
+
 	cmd.Log.Debug().Msg("done")
 
 	return nil
@@ -84,10 +104,12 @@ func (cmd *NetworkClientBlockItemFilesCommand) prepare(pctx context.Context) err
 			compressFormat string,
 			_ func(_ io.Reader, compressFormat string) error,
 		) (bool, bool, error) {
-			cmd.Log.Debug().
+			cmd.skipped.Add(1)
+
+			cmd.Log.Warn().
 				Stringer("uri", &uri).
 				Str("compress_format", compressFormat).
-				Msg("remote item found, but skip")
+				Msg("remote item found, but skipped by --no-download-remote-item")
 
 			return true, true, nil
 		}
@@ -95,41 +117,69 @@ func (cmd *NetworkClientBlockItemFilesCommand) prepare(pctx context.Context) err
 
 	cmd.decompressf = util.DecompressReaderFunc(util.DefaultDecompressReaderFunc)
 
+	if cmd.Workers < 1 {
+		cmd.Workers = defaultBlockItemDownloadWorkers
+	}
+
+	switch from, to, err := cmd.heightRange(pctx, cmd.HeightRange); {
+	case err != nil:
+		return err
+	default:
+		cmd.fromHeight, cmd.toHeight = from, to
+	}
+
 	cmd.Log.Debug().
-		Interface("height", cmd.Height.Height()).
+		Interface("from_height", cmd.fromHeight).
+		Interface("to_height", cmd.toHeight).
 		Str("output_directory", cmd.OutputDirectory).
 		Bool("download_remote_item", cmd.DownloadRemoteItem).
 		Bool("download_all_items", cmd.DownloadAllItems).
+		Int64("workers", cmd.Workers).
 		Msg("flags")
 
 	return nil
 }
 
-func (cmd *NetworkClientBlockItemFilesCommand) download(
-	kctx *kong.Context,
-	pctx context.Context, //revive:disable-line:context-as-argument
-) error {
+// download downloads the blocks of range concurrently; with output directory,
+// the downloaded blocks are saved in the same layout with the local block data
+// directory, so `storage import` can import them.
+func (cmd *NetworkClientBlockItemFilesCommand) download(pctx context.Context) error {
+	return util.RunJobWorker(pctx, cmd.Workers, (cmd.toHeight-cmd.fromHeight).Int64()+1,
+		func(ctx context.Context, i, _ uint64) error {
+			height := cmd.fromHeight + base.Height(int64(i))
+
+			if err := cmd.downloadHeight(ctx, height); err != nil {
+				return errors.WithMessagef(err, "height %d", height)
+			}
+
+			return nil
+		},
+	)
+}
+
+func (cmd *NetworkClientBlockItemFilesCommand) downloadHeight(pctx context.Context, height base.Height) error {
 	var bfiles base.BlockItemFiles
 
-	switch i, err := cmd.downloadBlockItemFiles(kctx, pctx); {
+	switch i, err := cmd.downloadBlockItemFiles(pctx, height); {
 	case err != nil:
 		return err
 	default:
 		bfiles = i
 	}
 
-	if cmd.DownloadAllItems {
-		if err := cmd.downloadBlockItems(kctx, pctx, bfiles); err != nil {
+	if cmd.DownloadAllItems && len(cmd.OutputDirectory) > 0 {
+		if err := cmd.downloadBlockItems(pctx, height, bfiles); err != nil {
 			return err
 		}
 	}
 
+	cmd.Log.Debug().Interface("height", height).Msg("block downloaded")
+
 	return nil
 }
 
 func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItemFiles(
-	kctx *kong.Context,
-	pctx context.Context, //revive:disable-line:context-as-argument
+	pctx context.Context, height base.Height,
 ) (
 	bfiles base.BlockItemFiles,
 	_ error,
@@ -140,16 +190,14 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItemFiles(
 	buf := bytes.NewBuffer(nil)
 	defer buf.Reset()
 
-	out := io.MultiWriter(os.Stdout, buf)
-
 	switch found, err := cmd.Client.BlockItemFiles(
 		ctx,
 		cmd.Remote.ConnInfo(),
-		cmd.Height.Height(),
+		height,
 		cmd.priv,
 		base.NetworkID(cmd.NetworkID),
 		func(r io.Reader) error {
-			_, err := io.Copy(out, r)
+			_, err := io.Copy(buf, r)
 
 			return errors.WithStack(err)
 		},
@@ -157,8 +205,18 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItemFiles(
 	case err != nil:
 		return nil, err
 	case !found:
-		kctx.Errorf(util.ErrNotFound.Errorf("block item files").Error())
-		kctx.Exit(2)
+		return nil, util.ErrNotFound.Errorf("block item files")
+	}
+
+	if len(cmd.OutputDirectory) < 1 {
+		// NOTE without output directory, print block item files.
+		cmd.outl.Lock()
+		_, err := os.Stdout.Write(buf.Bytes())
+		cmd.outl.Unlock()
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
 	}
 
 	if len(cmd.OutputDirectory) > 0 {
@@ -167,7 +225,7 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItemFiles(
 
 		or := io.TeeReader(buf, obuf)
 
-		fname := isaac.BlockItemFilesPath(cmd.OutputDirectory, cmd.Height.Height())
+		fname := isaac.BlockItemFilesPath(cmd.OutputDirectory, height)
 		if err := cmd.saveFile(or, fname); err != nil {
 			return nil, err
 		}
@@ -183,13 +241,13 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItemFiles(
 }
 
 func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItems(
-	kctx *kong.Context,
-	pctx context.Context, //revive:disable-line:context-as-argument
+	pctx context.Context,
+	height base.Height,
 	bfiles base.BlockItemFiles,
 ) error {
 	// NOTE remove height directory
 	if err := os.RemoveAll(filepath.Join(
-		cmd.OutputDirectory, isaac.BlockHeightDirectory(cmd.Height.Height()))); err != nil {
+		cmd.OutputDirectory, isaac.BlockHeightDirectory(height))); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -206,15 +264,14 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItems(
 		t := t
 
 		if err := worker.NewJob(func(ctx context.Context, _ uint64) error {
-			switch found, err := cmd.downloadBlockItem(ctx, t, m[t]); {
+			switch found, err := cmd.downloadBlockItem(ctx, height, t, m[t]); {
 			case err != nil:
 				return err
 			case !found:
-				kctx.Errorf(util.ErrNotFound.Errorf("block item file, %q", t.String()).Error())
-				kctx.Exit(2)
+				return util.ErrNotFound.Errorf("block item file, %q", t.String())
+			default:
+				return nil
 			}
-
-			return nil
 		}); err != nil {
 			return err
 		}
@@ -226,13 +283,13 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItems(
 }
 
 func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItem(
-	pctx context.Context, t base.BlockItemType, bf base.BlockItemFile,
+	pctx context.Context, height base.Height, t base.BlockItemType, bf base.BlockItemFile,
 ) (bool, error) {
 	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
 	defer cancel()
 
 	if !isaac.IsInLocalBlockItemFile(bf.URI()) {
-		return true, cmd.downloadRemoteItemFile(ctx, t, bf.URI(), bf.CompressFormat())
+		return true, cmd.downloadRemoteItemFile(ctx, height, t, bf.URI(), bf.CompressFormat())
 	}
 
 	cmd.Log.Debug().
@@ -242,14 +299,14 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadBlockItem(
 	switch found, err := cmd.Client.BlockItem(
 		ctx,
 		cmd.Remote.ConnInfo(),
-		cmd.Height.Height(),
+		height,
 		t,
 		func(r io.Reader, _ url.URL, _ string) error {
 			if r == nil {
 				return util.ErrNotFound.Errorf("block item file, %q", t.String())
 			}
 
-			return cmd.saveItemFile(r, bf.URI().Path)
+			return cmd.saveItemFile(r, height, bf.URI().Path)
 		},
 	); {
 	case err != nil, !found:
@@ -282,12 +339,12 @@ func (cmd *NetworkClientBlockItemFilesCommand) saveFile(r io.Reader, path string
 	}
 }
 
-func (cmd *NetworkClientBlockItemFilesCommand) saveItemFile(r io.Reader, name string) error {
+func (cmd *NetworkClientBlockItemFilesCommand) saveItemFile(r io.Reader, height base.Height, name string) error {
 	return cmd.saveFile(
 		r,
 		filepath.Join(
 			cmd.OutputDirectory,
-			isaac.BlockHeightDirectory(cmd.Height.Height()),
+			isaac.BlockHeightDirectory(height),
 			name,
 		),
 	)
@@ -295,6 +352,7 @@ func (cmd *NetworkClientBlockItemFilesCommand) saveItemFile(r io.Reader, name st
 
 func (cmd *NetworkClientBlockItemFilesCommand) downloadRemoteItemFile(
 	ctx context.Context,
+	height base.Height,
 	t base.BlockItemType,
 	uri url.URL,
 	compressFormat string,
@@ -343,7 +401,7 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadRemoteItemFile(
 
 			rr.Reset()
 
-			return cmd.saveItemFile(rr, fname)
+			return cmd.saveItemFile(rr, height, fname)
 		},
 	); {
 	case err != nil:
@@ -358,10 +416,14 @@ func (cmd *NetworkClientBlockItemFilesCommand) downloadRemoteItemFile(
 }
 
 type NetworkClientBlockItemFileCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
 	BaseNetworkClientCommand
-	Height   launch.HeightFlag  `arg:""`
-	Item     base.BlockItemType `arg:"item" help:"item type"`
-	Validate bool               `name:"validate" negatable:"" help:"validate by default" default:"true"`
+	HeightRange     HeightRangeFlag    `arg:"" name:"range" help:"<height>, <from>-<to> or <from>-; <from>- is until the last height of remote"`
+	Item            base.BlockItemType `arg:"item" help:"item type"`
+	OutputDirectory string             `name:"output-directory" help:"save item files in the layout of block data directory"`
+	Workers         int64              `name:"workers" help:"number of concurrent downloads with output directory" default:"8" placeholder:"count"`
+	Validate        bool               `name:"validate" negatable:"" help:"validate by default" default:"true"`
+	//revive:enable:line-length-limit
 }
 
 func (cmd *NetworkClientBlockItemFileCommand) Run(
@@ -379,29 +441,56 @@ func (cmd *NetworkClientBlockItemFileCommand) Run(
 		_ = cmd.Client.Close()
 	}()
 
-	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
-	defer cancel()
+	from, to, err := cmd.heightRange(pctx, cmd.HeightRange)
+	if err != nil {
+		return err
+	}
+
+	workers := cmd.Workers
 
-	switch found, err := cmd.downloadItem(ctx); {
+	switch {
+	case len(cmd.OutputDirectory) < 1:
+		// NOTE print items to stdout in order.
+		workers = 1
+	case workers < 1:
+		workers = defaultBlockItemDownloadWorkers
+	}
+
+	switch err := util.RunJobWorker(pctx, workers, (to-from).Int64()+1, func(ctx context.Context, i, _ uint64) error {
+		height := from + base.Height(int64(i))
+
+		switch found, err := cmd.downloadItem(ctx, height); {
+		case err != nil:
+			return errors.WithMessagef(err, "height %d", height)
+		case !found:
+			return util.ErrNotFound.Errorf("block item file, height %d", height)
+		default:
+			return nil
+		}
+	}); {
+	case errors.Is(err, util.ErrNotFound):
+		kctx.Errorf(err.Error())
+		kctx.Exit(2)
 	case err != nil:
 		return err
-	case !found:
-		kctx.Errorf(util.ErrNotFound.Errorf("block item file").Error())
-		kctx.Exit(2)
 	}
 
 	return nil
 }
 
-func (cmd *NetworkClientBlockItemFileCommand) downloadItem(ctx context.Context) (bool, error) {
+func (cmd *NetworkClientBlockItemFileCommand) downloadItem(pctx context.Context, height base.Height) (bool, error) {
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
 	switch found, err := cmd.Client.BlockItem(
 		ctx,
 		cmd.Remote.ConnInfo(),
-		cmd.Height.Height(),
+		height,
 		cmd.Item,
 		func(r io.Reader, uri url.URL, compressFormat string) error {
 			if r == nil {
 				cmd.Log.Info().
+					Interface("height", height).
 					Str("compress_format", compressFormat).
 					Stringer("uri", &uri).
 					Msg("remote uri found")
@@ -410,9 +499,14 @@ func (cmd *NetworkClientBlockItemFileCommand) downloadItem(ctx context.Context)
 			}
 
 			cmd.Log.Info().
+				Interface("height", height).
 				Str("compress_format", compressFormat).
 				Msg("found")
 
+			if len(cmd.OutputDirectory) > 0 {
+				return cmd.saveItem(r, height, uri)
+			}
+
 			_, err := io.Copy(os.Stdout, r)
 
 			return errors.WithStack(err)
@@ -424,3 +518,95 @@ func (cmd *NetworkClientBlockItemFileCommand) downloadItem(ctx context.Context)
 		return true, nil
 	}
 }
+
+func (cmd *NetworkClientBlockItemFileCommand) saveItem(r io.Reader, height base.Height, uri url.URL) error {
+	name := filepath.Base(uri.Path)
+	if len(uri.Path) < 1 || name == "/" || name == "." {
+		return errors.Errorf("empty item file name, %q", &uri)
+	}
+
+	path := filepath.Join(cmd.OutputDirectory, isaac.BlockHeightDirectory(height), name)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return errors.WithStack(err)
+	}
+
+	cmd.Log.Debug().Str("file", path).Msg("saved")
+
+	return nil
+}
+
+// HeightRangeFlag is launch.RangeFlag, but single height without "-" is the
+// range of the height itself, not until the last height.
+type HeightRangeFlag struct {
+	launch.RangeFlag
+	single bool
+}
+
+func (f *HeightRangeFlag) UnmarshalText(b []byte) error {
+	if err := f.RangeFlag.UnmarshalText(b); err != nil {
+		return err
+	}
+
+	s := strings.TrimSpace(string(b))
+
+	f.single = len(s) > 0 && !strings.Contains(s, "-")
+
+	return nil
+}
+
+func (f *HeightRangeFlag) To() *uint64 {
+	if f.single {
+		return f.RangeFlag.From()
+	}
+
+	return f.RangeFlag.To()
+}
+
+// heightRange returns the heights of range flag; without from height, genesis
+// height is used and without to height, the last height of remote is used.
+func (cmd *BaseNetworkClientCommand) heightRange(
+	pctx context.Context, f HeightRangeFlag,
+) (from base.Height, to base.Height, _ error) {
+	from = base.GenesisHeight
+
+	if h := f.From(); h != nil {
+		from = base.Height(*h)
+	}
+
+	switch h := f.To(); {
+	case h != nil:
+		to = base.Height(*h)
+	default:
+		ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+		defer cancel()
+
+		switch bm, found, err := cmd.Client.LastBlockMap(ctx, cmd.Remote.ConnInfo(), nil); {
+		case err != nil:
+			return from, to, err
+		case !found:
+			return from, to, util.ErrNotFound.Errorf("last blockmap of remote")
+		default:
+			to = bm.Manifest().Height()
+		}
+	}
+
+	if from > to {
+		return from, to, errors.Errorf("from height is higher than to; from=%d to=%d", from, to)
+	}
+
+	return from, to, nil
+}
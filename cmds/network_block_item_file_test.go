@@ -0,0 +1,52 @@
+package cmds
+
+import "testing"
+
+func TestHeightRangeFlag(t *testing.T) {
+	u := func(i uint64) *uint64 { return &i }
+
+	cases := []struct {
+		name string
+		s    string
+		from *uint64
+		to   *uint64
+		err  bool
+	}{
+		{name: "empty"},
+		{name: "single", s: "3", from: u(3), to: u(3)},
+		{name: "range", s: "3-9", from: u(3), to: u(9)},
+		{name: "until last", s: "3-", from: u(3)},
+		{name: "from genesis", s: "-9", to: u(9)},
+		{name: "wrong", s: "a-9", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var f HeightRangeFlag
+
+			err := f.UnmarshalText([]byte(c.s))
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			check := func(name string, expected, v *uint64) {
+				switch {
+				case expected == nil && v == nil:
+				case expected == nil, v == nil, *expected != *v:
+					t.Errorf("%s: expected %v, got %v", name, expected, v)
+				}
+			}
+
+			check("from", c.from, f.From())
+			check("to", c.to, f.To())
+		})
+	}
+}
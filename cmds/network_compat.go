@@ -0,0 +1,395 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alecthomas/kong"
+	"github.com/imfact-labs/imfact-model/runtime/spec"
+	isaacnetwork "github.com/imfact-labs/mitum2/isaac/network"
+	"github.com/imfact-labs/mitum2/launch"
+	quicstreamheader "github.com/imfact-labs/mitum2/network/quicstream/header"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/hint"
+	"github.com/pkg/errors"
+)
+
+const (
+	factCompatMissingInRemote = "missing-in-remote"
+	factCompatMissingInLocal  = "missing-in-local"
+	factCompatIncompatible    = "incompatible"
+	factCompatInvalidInRemote = "invalid-in-remote"
+	factCompatInvalidInLocal  = "invalid-in-local"
+)
+
+type NetworkClientCompatCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseNetworkClientCommand
+	Remotes []launch.ConnInfoFlag `arg:"" name:"remotes" optional:"" help:"other remote node conn info" placeholder:"ConnInfo"`
+	APIs    []string              `name:"api" help:"digest api url of remote node to load supported facts; remote without api is checked only by version" placeholder:"URL"`
+	Format  string                `name:"format" help:"output format, {table, json}" default:"table"`
+	//revive:enable:line-length-limit
+}
+
+type compatReport struct {
+	Local        compatNode   `json:"local"`
+	Remotes      []compatNode `json:"remotes"`
+	Incompatible bool         `json:"incompatible"`
+}
+
+type compatNode struct {
+	Remote            string       `json:"remote,omitempty"`
+	Address           string       `json:"address,omitempty"`
+	Version           string       `json:"version,omitempty"`
+	Error             string       `json:"error,omitempty"`
+	SupportedFacts    []string     `json:"supported_facts,omitempty"`
+	Facts             []factCompat `json:"facts,omitempty"`
+	VersionCompatible bool         `json:"version_compatible"`
+	FactsKnown        bool         `json:"facts_known"`
+}
+
+type factCompat struct {
+	Type   string `json:"type"`
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	Status string `json:"status"`
+}
+
+func (cmd *NetworkClientCompatCommand) Run(
+	kctx *kong.Context, pctx context.Context, //revive:disable-line:context-as-argument
+) error {
+	switch cmd.Format {
+	case "table", "json":
+	default:
+		return errors.Errorf("unsupported format, %q", cmd.Format)
+	}
+
+	switch incompatible, err := cmd.run(pctx); {
+	case err != nil:
+		return err
+	case incompatible:
+		kctx.Exit(1)
+	}
+
+	return nil
+}
+
+func (cmd *NetworkClientCompatCommand) run(pctx context.Context) (bool, error) {
+	if err := cmd.Prepare(pctx); err != nil {
+		return false, err
+	}
+
+	defer func() {
+		_ = cmd.Client.Close()
+	}()
+
+	var version util.Version
+	if err := util.LoadFromContextOK(pctx, launch.VersionContextKey, &version); err != nil {
+		return false, err
+	}
+
+	report := compatReport{
+		Local: compatNode{
+			Version:           version.String(),
+			SupportedFacts:    hintStrings(spec.SupportedProposalOperationFactHinters),
+			VersionCompatible: true,
+			FactsKnown:        true,
+		},
+	}
+
+	apis := map[string]nodeModulesInfo{}
+
+	for i := range cmd.APIs {
+		switch info, err := cmd.fetchNodeModules(pctx, cmd.APIs[i]); {
+		case err != nil:
+			cmd.Log.Error().Err(err).Str("api", cmd.APIs[i]).Msg("failed to load node modules")
+		default:
+			apis[info.Address] = info
+		}
+	}
+
+	remotes := append([]launch.ConnInfoFlag{cmd.Remote}, cmd.Remotes...)
+	report.Remotes = make([]compatNode, len(remotes))
+
+	for i := range remotes {
+		n := cmd.compareRemote(pctx, remotes[i], version, report.Local.SupportedFacts, apis)
+		if n.isIncompatible() {
+			report.Incompatible = true
+		}
+
+		report.Remotes[i] = n
+	}
+
+	if err := cmd.printReport(report); err != nil {
+		return false, err
+	}
+
+	return report.Incompatible, nil
+}
+
+func (cmd *NetworkClientCompatCommand) compareRemote(
+	pctx context.Context,
+	remote launch.ConnInfoFlag,
+	version util.Version,
+	localFacts []string,
+	apis map[string]nodeModulesInfo,
+) compatNode {
+	n := compatNode{Remote: remote.String()}
+
+	switch info, err := cmd.fetchNodeInfo(pctx, remote); {
+	case err != nil:
+		n.Error = err.Error()
+
+		return n
+	default:
+		n.Address = info.Address().String()
+		n.Version = info.Version().String()
+		n.VersionCompatible = version.IsCompatible(info.Version())
+	}
+
+	if m, found := apis[n.Address]; found {
+		n.FactsKnown = true
+
+		for i := range m.Modules {
+			n.SupportedFacts = append(n.SupportedFacts, m.Modules[i].SupportedFacts...)
+		}
+
+		n.Facts = compareSupportedFacts(localFacts, n.SupportedFacts)
+	}
+
+	return n
+}
+
+// isIncompatible checks the version and the known supported facts; without
+// supported facts, like the node of previous release or without digest, only
+// the version is checked.
+func (n compatNode) isIncompatible() bool {
+	return len(n.Error) > 0 || !n.VersionCompatible || len(n.Facts) > 0
+}
+
+func (cmd *NetworkClientCompatCommand) fetchNodeInfo(
+	pctx context.Context, remote launch.ConnInfoFlag,
+) (info isaacnetwork.NodeInfo, _ error) {
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
+	stream, _, err := cmd.Client.Dial(ctx, remote.ConnInfo())
+	if err != nil {
+		return info, err
+	}
+
+	err = stream(ctx, func(ctx context.Context, broker *quicstreamheader.ClientBroker) error {
+		switch i, found, err := cmd.Client.NodeInfo(ctx, broker); {
+		case err != nil:
+			return err
+		case !found:
+			return util.ErrNotFound.Errorf("node info")
+		default:
+			info = i
+
+			return nil
+		}
+	})
+
+	return info, err
+}
+
+func (cmd *NetworkClientCompatCommand) fetchNodeModules(pctx context.Context, api string) (nodeModulesInfo, error) {
+	var info nodeModulesInfo
+
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(api, "/")+HandlerPathNodeModules, nil)
+	if err != nil {
+		return info, errors.WithStack(err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return info, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return info, errors.Errorf("unexpected status, %q", res.Status)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return info, errors.WithStack(err)
+	}
+
+	if err := util.UnmarshalJSON(b, &info); err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
+
+func (cmd *NetworkClientCompatCommand) printReport(report compatReport) error {
+	if cmd.Format == "json" {
+		return cmd.Print(report, cmd.Out)
+	}
+
+	w := tabwriter.NewWriter(cmd.Out, 0, 0, 2, ' ', 0) //nolint:gomnd //...
+
+	_, _ = fmt.Fprintf(w, "local version: %s\n\n", report.Local.Version)
+	_, _ = fmt.Fprintln(w, "REMOTE\tADDRESS\tVERSION\tVERSION COMPATIBLE\tFACTS")
+
+	for i := range report.Remotes {
+		n := report.Remotes[i]
+
+		var facts string
+
+		switch {
+		case len(n.Error) > 0:
+			facts = "error: " + n.Error
+		case !n.FactsKnown:
+			facts = "unknown (version-only check)"
+		case len(n.Facts) < 1:
+			facts = "compatible"
+		default:
+			facts = fmt.Sprintf("%d mismatched", len(n.Facts))
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\n", n.Remote, n.Address, n.Version, n.VersionCompatible, facts)
+	}
+
+	for i := range report.Remotes {
+		n := report.Remotes[i]
+
+		if len(n.Facts) < 1 {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "\n## %s\n", n.Remote)
+		_, _ = fmt.Fprintln(w, "TYPE\tLOCAL\tREMOTE\tSTATUS")
+
+		for j := range n.Facts {
+			f := n.Facts[j]
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Type, f.Local, f.Remote, f.Status)
+		}
+	}
+
+	return errors.WithStack(w.Flush())
+}
+
+// compareSupportedFacts returns the mismatched facts between local and
+// remote; facts of same type are compatible when every version of one side is
+// accepted by the other side. The invalid hints are also mismatched.
+func compareSupportedFacts(local, remote []string) []factCompat {
+	lm, linvalids := hintsByType(local)
+	rm, rinvalids := hintsByType(remote)
+
+	types := make([]string, 0, len(lm)+len(rm))
+
+	for t := range lm {
+		types = append(types, t)
+	}
+
+	for t := range rm {
+		if _, found := lm[t]; !found {
+			types = append(types, t)
+		}
+	}
+
+	sort.Strings(types)
+
+	var facts []factCompat
+
+	for i := range types {
+		l, lfound := lm[types[i]]
+		r, rfound := rm[types[i]]
+
+		f := factCompat{Type: types[i]}
+
+		switch {
+		case !rfound:
+			f.Local = joinHints(l)
+			f.Status = factCompatMissingInRemote
+		case !lfound:
+			f.Remote = joinHints(r)
+			f.Status = factCompatMissingInLocal
+		case isCompatibleHints(l, r) && isCompatibleHints(r, l):
+			continue
+		default:
+			f.Local = joinHints(l)
+			f.Remote = joinHints(r)
+			f.Status = factCompatIncompatible
+		}
+
+		facts = append(facts, f)
+	}
+
+	for i := range linvalids {
+		facts = append(facts, factCompat{Type: linvalids[i], Local: linvalids[i], Status: factCompatInvalidInLocal})
+	}
+
+	for i := range rinvalids {
+		facts = append(facts, factCompat{Type: rinvalids[i], Remote: rinvalids[i], Status: factCompatInvalidInRemote})
+	}
+
+	return facts
+}
+
+// hintsByType groups the hints by type; the strings failed to be parsed are
+// returned as invalids.
+func hintsByType(hs []string) (map[string][]hint.Hint, []string) {
+	m := map[string][]hint.Hint{}
+
+	var invalids []string
+
+	for i := range hs {
+		ht, err := hint.ParseHint(hs[i])
+		if err != nil {
+			invalids = append(invalids, hs[i])
+
+			continue
+		}
+
+		m[ht.Type().String()] = append(m[ht.Type().String()], ht)
+	}
+
+	return m, invalids
+}
+
+// isCompatibleHints checks whether every hint of a is compatible with one of b.
+func isCompatibleHints(a, b []hint.Hint) bool {
+	for i := range a {
+		var found bool
+
+		for j := range b {
+			if a[i].IsCompatible(b[j]) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func joinHints(hs []hint.Hint) string {
+	ss := make([]string, len(hs))
+
+	for i := range hs {
+		ss[i] = hs[i].String()
+	}
+
+	return strings.Join(ss, ",")
+}
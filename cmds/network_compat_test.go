@@ -0,0 +1,90 @@
+package cmds
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareSupportedFacts(t *testing.T) {
+	cases := []struct {
+		name     string
+		local    []string
+		remote   []string
+		statuses []string
+	}{
+		{
+			name:   "same",
+			local:  []string{"a-fact-v0.0.1", "b-fact-v0.0.1"},
+			remote: []string{"b-fact-v0.0.1", "a-fact-v0.0.1"},
+		},
+		{
+			name:   "compatible version",
+			local:  []string{"a-fact-v0.0.1"},
+			remote: []string{"a-fact-v0.1.0"},
+		},
+		{
+			name:     "incompatible version",
+			local:    []string{"a-fact-v0.0.1"},
+			remote:   []string{"a-fact-v1.0.0"},
+			statuses: []string{factCompatIncompatible},
+		},
+		{
+			name:     "missing",
+			local:    []string{"a-fact-v0.0.1", "b-fact-v0.0.1"},
+			remote:   []string{"a-fact-v0.0.1", "c-fact-v0.0.1"},
+			statuses: []string{factCompatMissingInRemote, factCompatMissingInLocal},
+		},
+		{
+			name:     "invalid",
+			local:    []string{"a-fact-v0.0.1", "a-fact"},
+			remote:   []string{"a-fact-v0.0.1", "b-fact"},
+			statuses: []string{factCompatInvalidInLocal, factCompatInvalidInRemote},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			facts := compareSupportedFacts(c.local, c.remote)
+
+			statuses := make([]string, len(facts))
+
+			for i := range facts {
+				statuses[i] = facts[i].Status
+			}
+
+			if len(statuses) < 1 && len(c.statuses) < 1 {
+				return
+			}
+
+			if !reflect.DeepEqual(statuses, c.statuses) {
+				t.Errorf("expected %v, got %v", c.statuses, statuses)
+			}
+		})
+	}
+}
+
+func TestCompatNodeIsIncompatible(t *testing.T) {
+	cases := []struct {
+		name         string
+		node         compatNode
+		incompatible bool
+	}{
+		{name: "compatible", node: compatNode{VersionCompatible: true, FactsKnown: true}},
+		{name: "facts unknown", node: compatNode{VersionCompatible: true}},
+		{name: "facts unknown and version", node: compatNode{}, incompatible: true},
+		{
+			name:         "facts mismatched",
+			node:         compatNode{VersionCompatible: true, FactsKnown: true, Facts: []factCompat{{Type: "a-fact"}}},
+			incompatible: true,
+		},
+		{name: "error", node: compatNode{Error: "failed"}, incompatible: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if i := c.node.isIncompatible(); i != c.incompatible {
+				t.Errorf("expected %v, got %v", c.incompatible, i)
+			}
+		})
+	}
+}
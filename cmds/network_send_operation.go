@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
+
+	"github.com/alecthomas/kong"
 
 	"github.com/imfact-labs/mitum2/base"
 	"github.com/imfact-labs/mitum2/isaac"
@@ -11,7 +14,6 @@ import (
 	"github.com/imfact-labs/mitum2/launch"
 	launchcmd "github.com/imfact-labs/mitum2/launch/cmd"
 	"github.com/imfact-labs/mitum2/util"
-	"github.com/imfact-labs/mitum2/util/encoder"
 	"github.com/imfact-labs/mitum2/util/hint"
 	"github.com/pkg/errors"
 )
@@ -21,13 +23,17 @@ type NetworkClientCommand struct { //nolint:govet //...
 	//revive:disable:nested-structs
 	NodeInfo      launchcmd.NetworkClientNodeInfoCommand     `cmd:"" name:"node-info" help:"remote node info"`
 	SendOperation NetworkClientSendOperationCommand          `cmd:"" name:"send-operation" help:"send operation"`
-	State         launchcmd.NetworkClientStateCommand        `cmd:"" name:"state" help:"get state"`
+	State         NetworkClientStateCommand                  `cmd:"" name:"state" help:"get state"`
+	StateDiff     NetworkClientStateDiffCommand              `cmd:"" name:"state-diff" help:"compare states of remote nodes"`
 	LastBlockMap  launchcmd.NetworkClientLastBlockMapCommand `cmd:"" name:"last-blockmap" help:"get last blockmap"`
 	Design        struct {
 		Read  NetworkClientReadNodeCommand  `cmd:"" name:"read" help:"read node value"`
 		Write NetworkClientWriteNodeCommand `cmd:"" name:"write" help:"write node value"`
 	} `cmd:"" name:"design" help:""`
-	Event launchcmd.NetworkClientEventLoggingCommand `cmd:"" name:"event" help:"event log"`
+	Event          launchcmd.NetworkClientEventLoggingCommand `cmd:"" name:"event" help:"event log"`
+	Compat         NetworkClientCompatCommand                 `cmd:"" name:"compat" help:"check compatibility of remote nodes"`
+	BlockItemFiles NetworkClientBlockItemFilesCommand         `cmd:"" name:"block-item-files" help:"download block item files of height range"`
+	BlockItemFile  NetworkClientBlockItemFileCommand          `cmd:"" name:"block-item-file" help:"download block item file of height range"`
 	//revive:enable:nested-structs
 	//revive:enable:line-length-limit
 }
@@ -99,35 +105,103 @@ func (cmd *BaseNetworkClientCommand) Print(v interface{}, out io.Writer) error {
 }
 
 type NetworkClientSendOperationCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
 	BaseNetworkClientCommand
-	Input    string `arg:"" name:"input" help:"input; default is stdin" default:"-"`
-	IsString bool   `name:"input.is-string" help:"input is string, not file"`
+	OperationTemplateFlags
+	Input        string                `arg:"" name:"input" help:"input, json or yaml; default is stdin" default:"-"`
+	IsString     bool                  `name:"input.is-string" help:"input is string, not file"`
+	Bulk         bool                  `name:"bulk" help:"input is json lines of operations or directory of operation files"`
+	Check        bool                  `name:"check" help:"validate operations before sending; if any is invalid, nothing is sent"`
+	Remotes      []launch.ConnInfoFlag `name:"remotes" help:"other remote nodes; operations are sent to every remote" placeholder:"ConnInfo"`
+	Concurrency  int64                 `name:"concurrency" help:"number of concurrent sends" default:"8" placeholder:"count"`
+	Rate         float64               `name:"rate" help:"max sends per second; 0 is unlimited" default:"0" placeholder:"count"`
+	Wait         bool                  `name:"wait" help:"wait until operation is included in block; exit code is 1 for rejected and 2 for timeout"`
+	WaitTimeout  time.Duration         `name:"wait-timeout" help:"timeout to wait operation" default:"1m" placeholder:"duration"`
+	WaitInterval time.Duration         `name:"wait-interval" help:"interval to check operation" default:"1s" placeholder:"duration"`
+	API          string                `name:"api" help:"digest api url to check operation; without api, blocks are read from remote node" placeholder:"URL"`
+	//revive:enable:line-length-limit
 }
 
-func (cmd *NetworkClientSendOperationCommand) Run(pctx context.Context) error {
-	if err := cmd.Prepare(pctx); err != nil {
+func (cmd *NetworkClientSendOperationCommand) Run(
+	kctx *kong.Context, pctx context.Context, //revive:disable-line:context-as-argument
+) error {
+	switch code, err := cmd.run(pctx); {
+	case err != nil:
 		return err
+	case code > 0:
+		kctx.Exit(code)
+	}
+
+	return nil
+}
+
+// run returns the exit code; the client is closed before exit.
+func (cmd *NetworkClientSendOperationCommand) run(pctx context.Context) (int, error) {
+	if err := cmd.Prepare(pctx); err != nil {
+		return 0, err
 	}
 
 	defer func() {
 		_ = cmd.Client.Close()
 	}()
 
-	var op base.Operation
+	switch {
+	case cmd.Wait && (cmd.WaitTimeout < 1 || cmd.WaitInterval < 1):
+		return 0, errors.Errorf("wait-timeout and wait-interval should be over zero")
+	case cmd.Concurrency < 1:
+		return 0, errors.Errorf("concurrency should be over zero, %d", cmd.Concurrency)
+	case cmd.Rate < 0:
+		return 0, errors.Errorf("negative rate, %v", cmd.Rate)
+	}
+
+	vars, err := cmd.templateVariables()
+	if err != nil {
+		return 0, err
+	}
 
-	switch i, err := launch.LoadInputFlag(cmd.Input, !cmd.IsString); {
-	case err != nil:
-		return err
-	case len(i) < 1:
-		return errors.Errorf("empty input")
-	default:
-		cmd.Log.Debug().
-			Str("input", string(i)).
-			Msg("input")
+	ops, err := loadOperations(cmd.Encoder, cmd.Input, cmd.IsString, cmd.Bulk, vars)
+	if err != nil {
+		return 0, err
+	}
+
+	if cmd.Check {
+		if vs := validateOperations(ops, base.NetworkID([]byte(cmd.NetworkID))); !isValidOperations(vs) {
+			cmd.Log.Error().Msg("invalid operations; not sent")
+
+			if err := printOperationValidations(cmd.Out, "json", invalidOperationValidations(vs)); err != nil {
+				return 0, err
+			}
+
+			return 1, nil
+		}
+	}
+
+	if cmd.Bulk || len(cmd.Remotes) > 0 {
+		return cmd.sendOperations(pctx, ops)
+	}
+
+	op := ops[0]
+
+	cmd.Log.Debug().
+		Interface("operation", op).
+		Msg("input")
 
-		if err := encoder.Decode(cmd.Encoder, i, &op); err != nil {
-			return err
+	var tracker *operationTracker
+
+	if cmd.Wait {
+		i, err := newOperationTracker(cmd.Encoders, cmd.Client, cmd.Remote.ConnInfo(), cmd.API, cmd.Timeout)
+		if err != nil {
+			return 0, err
+		}
+
+		defer i.close()
+
+		// NOTE the blocks after sent are checked.
+		if err := i.start(pctx); err != nil {
+			return 0, err
 		}
+
+		tracker = i
 	}
 
 	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
@@ -137,12 +211,41 @@ func (cmd *NetworkClientSendOperationCommand) Run(pctx context.Context) error {
 	case err != nil:
 		cmd.Log.Error().Err(err).Msg("not sent")
 
-		return err
+		return 0, err
 	case !sent:
-		cmd.Log.Error().Msg("not sent")
+		// NOTE the remote already has the operation, so it can be included in
+		// block; keep waiting like the bulk sending.
+		cmd.Log.Warn().Str("status", operationStatusDuplicated).Msg("not sent; already known by remote")
 	default:
 		cmd.Log.Info().Msg("sent")
 	}
 
-	return nil
+	if tracker == nil {
+		return 0, nil
+	}
+
+	var results []operationResult
+
+	if err := tracker.wait(pctx, []util.Hash{op.Fact().Hash()}, cmd.WaitInterval, cmd.WaitTimeout,
+		func(r operationResult) error {
+			results = append(results, r)
+
+			return nil
+		},
+	); err != nil {
+		return 0, err
+	}
+
+	return cmd.printResults(results)
+}
+
+// printResults prints the results and returns the exit code of results.
+func (cmd *NetworkClientSendOperationCommand) printResults(results []operationResult) (int, error) {
+	for i := range results {
+		if err := cmd.Print(results[i], cmd.Out); err != nil {
+			return 0, err
+		}
+	}
+
+	return operationResultsExitCode(results), nil
 }
@@ -0,0 +1,242 @@
+package cmds
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+const (
+	operationStatusSent       = "sent"
+	operationStatusDuplicated = "duplicated"
+	operationStatusError      = "error"
+
+	operationStageSend = "send"
+	operationStageWait = "wait"
+)
+
+type sendOperationsSummary struct {
+	Stage          string           `json:"stage"`
+	Statuses       map[string]int64 `json:"statuses"`
+	Operations     int              `json:"operations"`
+	Remotes        int              `json:"remotes"`
+	ElapsedSeconds float64          `json:"elapsed_seconds"`
+	SendsPerSecond float64          `json:"sends_per_second"`
+}
+
+// sendOperations sends the operations to every remote concurrently and prints
+// the result of each send as json lines with the summary at the end; it
+// returns the exit code.
+func (cmd *NetworkClientSendOperationCommand) sendOperations(
+	pctx context.Context, ops []base.Operation,
+) (int, error) {
+	remotes := append([]launch.ConnInfoFlag{cmd.Remote}, cmd.Remotes...)
+
+	cmd.Log.Debug().
+		Int("operations", len(ops)).
+		Int("remotes", len(remotes)).
+		Int64("concurrency", cmd.Concurrency).
+		Float64("rate", cmd.Rate).
+		Msg("operations loaded")
+
+	var tracker *operationTracker
+
+	if cmd.Wait {
+		i, err := newOperationTracker(cmd.Encoders, cmd.Client, cmd.Remote.ConnInfo(), cmd.API, cmd.Timeout)
+		if err != nil {
+			return 0, err
+		}
+
+		defer i.close()
+
+		if err := i.start(pctx); err != nil {
+			return 0, err
+		}
+
+		tracker = i
+	}
+
+	var limiter *rate.Limiter
+	if cmd.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(cmd.Rate), 1)
+	}
+
+	results := make([]operationResult, len(ops)*len(remotes))
+
+	var printl sync.Mutex
+
+	started := time.Now()
+
+	if err := util.RunJobWorker(pctx, cmd.Concurrency, int64(len(results)),
+		func(ctx context.Context, i, _ uint64) error {
+			o, r := int(i)/len(remotes), int(i)%len(remotes)
+
+			if limiter != nil {
+				if err := limiter.Wait(ctx); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+
+			results[i] = cmd.sendOperation(ctx, remotes[r], ops[o])
+
+			printl.Lock()
+			defer printl.Unlock()
+
+			return cmd.printLine(results[i])
+		},
+	); err != nil {
+		return 0, err
+	}
+
+	elapsed := time.Since(started)
+
+	code := sendOperationsExitCode(results)
+
+	if tracker != nil {
+		var waited []operationResult
+
+		if err := tracker.wait(pctx, sentFactHashes(ops, results, len(remotes)), cmd.WaitInterval, cmd.WaitTimeout,
+			func(r operationResult) error {
+				r.Stage = operationStageWait
+				waited = append(waited, r)
+
+				return cmd.printLine(r)
+			},
+		); err != nil {
+			return 0, err
+		}
+
+		if i := operationResultsExitCode(waited); i > code {
+			code = i
+		}
+
+		results = append(results, waited...)
+	}
+
+	if err := cmd.printLine(newSendOperationsSummary(results, len(ops), len(remotes), elapsed)); err != nil {
+		return 0, err
+	}
+
+	return code, nil
+}
+
+func (cmd *NetworkClientSendOperationCommand) sendOperation(
+	pctx context.Context, remote launch.ConnInfoFlag, op base.Operation,
+) operationResult {
+	r := operationResult{
+		FactHash: op.Fact().Hash().String(),
+		Remote:   remote.String(),
+		Stage:    operationStageSend,
+	}
+
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
+	sent, err := cmd.Client.SendOperation(ctx, remote.ConnInfo(), op)
+
+	switch {
+	case err == nil && sent:
+		r.Status = operationStatusSent
+	case err == nil:
+		// NOTE the remote already has the operation.
+		r.Status = operationStatusDuplicated
+	case isNetworkError(err):
+		r.Status = operationStatusError
+		r.Reason = err.Error()
+	default:
+		r.Status = operationStatusRejected
+		r.Reason = err.Error()
+	}
+
+	return r
+}
+
+func (cmd *NetworkClientSendOperationCommand) printLine(v interface{}) error {
+	b, err := util.MarshalJSON(v)
+	if err != nil {
+		return err
+	}
+
+	cmd.print("%s", string(b))
+
+	return nil
+}
+
+func newSendOperationsSummary(
+	results []operationResult, ops, remotes int, elapsed time.Duration,
+) sendOperationsSummary {
+	s := sendOperationsSummary{
+		Stage:          "summary",
+		Statuses:       map[string]int64{},
+		Operations:     ops,
+		Remotes:        remotes,
+		ElapsedSeconds: elapsed.Seconds(),
+	}
+
+	var sends int64
+
+	for i := range results {
+		s.Statuses[results[i].Stage+"."+results[i].Status]++
+
+		if results[i].Stage == operationStageSend {
+			sends++
+		}
+	}
+
+	if elapsed > 0 {
+		s.SendsPerSecond = float64(sends) / elapsed.Seconds()
+	}
+
+	return s
+}
+
+// sendOperationsExitCode returns 1 if any send is rejected or failed.
+func sendOperationsExitCode(results []operationResult) int {
+	for i := range results {
+		switch results[i].Status {
+		case operationStatusRejected, operationStatusError:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// sentFactHashes returns the fact hashes of operations, which are accepted by
+// any remote.
+func sentFactHashes(ops []base.Operation, results []operationResult, remotes int) []util.Hash {
+	var hs []util.Hash
+
+	for i := range ops {
+		for j := 0; j < remotes; j++ {
+			switch results[i*remotes+j].Status {
+			case operationStatusSent, operationStatusDuplicated:
+				hs = append(hs, ops[i].Fact().Hash())
+			default:
+				continue
+			}
+
+			break
+		}
+	}
+
+	return hs
+}
+
+func isNetworkError(err error) bool {
+	var nerr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return true
+	default:
+		return errors.As(err, &nerr)
+	}
+}
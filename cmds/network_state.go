@@ -2,18 +2,39 @@ package cmds
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/imfact-labs/mitum2/base"
 	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/localtime"
 	"github.com/imfact-labs/mitum2/util/valuehash"
 	"github.com/pkg/errors"
 )
 
 type NetworkClientStateCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
 	BaseNetworkClientCommand
-	Key  string `arg:"" name:"state key" help:"state key"`
-	Hash string `arg:"" name:"state hash" help:"state hash" default:""`
+	Key      string        `arg:"" name:"state key" help:"state key" optional:""`
+	Hash     string        `arg:"" name:"state hash" help:"state hash" default:""`
+	KeysFile string        `name:"keys-file" help:"file of state keys, one key in one line; '-' is stdin" placeholder:"file"`
+	Workers  int64         `name:"workers" help:"number of workers to fetch states" default:"8" placeholder:"count"`
+	Watch    bool          `name:"watch" help:"watch states and print the changes as json lines"`
+	Interval time.Duration `name:"interval" help:"interval to poll states in watch mode" default:"3s" placeholder:"duration"`
+	//revive:enable:line-length-limit
+}
+
+type stateLine struct {
+	ObservedAt     time.Time   `json:"observed_at,omitempty"`
+	State          base.State  `json:"state,omitempty"`
+	Key            string      `json:"key"`
+	Hash           string      `json:"hash,omitempty"`
+	Error          string      `json:"error,omitempty"`
+	Height         base.Height `json:"height"`
+	ObservedHeight base.Height `json:"observed_height,omitempty"`
+	Found          bool        `json:"found"`
 }
 
 func (cmd *NetworkClientStateCommand) Run(pctx context.Context) error {
@@ -25,10 +46,63 @@ func (cmd *NetworkClientStateCommand) Run(pctx context.Context) error {
 		_ = cmd.Client.Close()
 	}()
 
-	if len(strings.TrimSpace(cmd.Key)) < 1 {
-		return errors.Errorf("empty state key")
+	keys, err := cmd.stateKeys()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cmd.Workers < 1:
+		return errors.Errorf("workers should be over zero, %d", cmd.Workers)
+	case cmd.Watch && cmd.Interval < 1:
+		return errors.Errorf("interval should be over zero, %v", cmd.Interval)
+	case len(keys) > 1 && len(strings.TrimSpace(cmd.Hash)) > 0:
+		return errors.Errorf("state hash only for single state key")
+	}
+
+	switch {
+	case cmd.Watch:
+		return cmd.watch(pctx, keys)
+	case len(cmd.KeysFile) > 0:
+		return cmd.printStates(pctx, keys)
+	default:
+		return cmd.printState(pctx, keys[0])
+	}
+}
+
+func (cmd *NetworkClientStateCommand) stateKeys() ([]string, error) {
+	var keys []string
+
+	if k := strings.TrimSpace(cmd.Key); len(k) > 0 {
+		keys = append(keys, k)
+	}
+
+	switch {
+	case len(cmd.KeysFile) < 1:
+	case cmd.KeysFile == "-":
+		i, err := readLines(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, i...)
+	default:
+		i, err := readLinesFile(cmd.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, i...)
+	}
+
+	if len(keys) < 1 {
+		return nil, errors.Errorf("empty state key")
 	}
 
+	return keys, nil
+}
+
+func (cmd *NetworkClientStateCommand) printState(pctx context.Context, key string) error {
 	var h util.Hash
 
 	if len(strings.TrimSpace(cmd.Hash)) > 0 {
@@ -38,7 +112,7 @@ func (cmd *NetworkClientStateCommand) Run(pctx context.Context) error {
 	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
 	defer cancel()
 
-	switch st, found, err := cmd.Client.State(ctx, cmd.Remote.ConnInfo(), cmd.Key, h); {
+	switch st, found, err := cmd.Client.State(ctx, cmd.Remote.ConnInfo(), key, h); {
 	case err != nil:
 		cmd.Log.Error().Err(err).Msg("failed to get state")
 
@@ -55,3 +129,150 @@ func (cmd *NetworkClientStateCommand) Run(pctx context.Context) error {
 		return cmd.Print(st, os.Stdout)
 	}
 }
+
+// printStates fetches the states concurrently and prints them as json lines
+// in the order of keys.
+func (cmd *NetworkClientStateCommand) printStates(pctx context.Context, keys []string) error {
+	lines := make([]stateLine, len(keys))
+
+	if err := util.RunJobWorker(pctx, cmd.Workers, int64(len(keys)),
+		func(ctx context.Context, i, _ uint64) error {
+			lines[i] = cmd.fetchState(ctx, keys[i], nil)
+
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	for i := range lines {
+		if err := cmd.printLine(lines[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// watch polls the states with the last known hashes and prints the changed
+// states with the last height of remote, at which the change is observed. The
+// last height is fetched after the states, so the observed height is not lower
+// than the height of changed state. When the known state disappears, the line
+// without state is printed.
+func (cmd *NetworkClientStateCommand) watch(pctx context.Context, keys []string) error {
+	hashes := make([]util.Hash, len(keys))
+
+	if h := strings.TrimSpace(cmd.Hash); len(h) > 0 {
+		hashes[0] = valuehash.NewBytesFromString(h)
+	}
+
+	ticker := time.NewTicker(cmd.Interval)
+	defer ticker.Stop()
+
+	for {
+		lines := make([]*stateLine, len(keys))
+
+		if err := util.RunJobWorker(pctx, cmd.Workers, int64(len(keys)),
+			func(ctx context.Context, i, _ uint64) error {
+				line := cmd.fetchState(ctx, keys[i], hashes[i])
+
+				switch {
+				case len(line.Error) > 0:
+					cmd.Log.Error().Str("key", keys[i]).Str("error", line.Error).Msg("failed to get state")
+				case !line.Found:
+					if hashes[i] != nil {
+						// NOTE known state disappeared.
+						hashes[i] = nil
+						lines[i] = &line
+					}
+				case line.State != nil:
+					hashes[i] = line.State.Hash()
+					lines[i] = &line
+				}
+
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+
+		var observed *base.Height
+
+		for i := range lines {
+			if lines[i] == nil {
+				continue
+			}
+
+			if observed == nil {
+				h := cmd.lastHeight(pctx)
+				observed = &h
+			}
+
+			lines[i].ObservedHeight = *observed
+
+			if err := cmd.printLine(*lines[i]); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-pctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cmd *NetworkClientStateCommand) fetchState(pctx context.Context, key string, h util.Hash) stateLine {
+	line := stateLine{Key: key, Height: base.NilHeight}
+
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
+	st, found, err := cmd.Client.State(ctx, cmd.Remote.ConnInfo(), key, h)
+
+	line.ObservedAt = localtime.Now().UTC()
+
+	switch {
+	case err != nil:
+		line.Error = err.Error()
+	case !found:
+	case st == nil:
+		// NOTE not changed from h.
+		line.Found = true
+	default:
+		line.Found = true
+		line.State = st
+		line.Hash = st.Hash().String()
+		line.Height = st.Height()
+	}
+
+	return line
+}
+
+func (cmd *NetworkClientStateCommand) lastHeight(pctx context.Context) base.Height {
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
+	switch bm, found, err := cmd.Client.LastBlockMap(ctx, cmd.Remote.ConnInfo(), nil); {
+	case err != nil:
+		cmd.Log.Error().Err(err).Msg("failed to get last blockmap")
+
+		return base.NilHeight
+	case !found:
+		return base.NilHeight
+	default:
+		return bm.Manifest().Height()
+	}
+}
+
+func (cmd *NetworkClientStateCommand) printLine(line stateLine) error {
+	b, err := util.MarshalJSON(line)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(cmd.Out, string(b))
+
+	return errors.WithStack(err)
+}
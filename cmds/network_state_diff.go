@@ -0,0 +1,288 @@
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alecthomas/kong"
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	stateDiffSame     = "same"
+	stateDiffDiverged = "diverged"
+	stateDiffError    = "error"
+)
+
+type NetworkClientStateDiffCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseNetworkClientCommand
+	Remotes  []launch.ConnInfoFlag `arg:"" name:"remotes" help:"other remote node conn info" placeholder:"ConnInfo"`
+	Keys     []string              `name:"key" help:"state key to compare; the full state keys are required, keys are not enumerated by model prefix" placeholder:"key"`
+	KeysFile string                `name:"keys-file" help:"file of state keys, one key in one line" type:"existingfile"`
+	Workers  int64                 `name:"workers" help:"number of workers to fetch states" default:"8" placeholder:"count"`
+	Format   string                `name:"format" help:"output format, {table, json}" default:"table"`
+	//revive:enable:line-length-limit
+}
+
+type stateDiffReport struct {
+	Remotes  []string    `json:"remotes"`
+	States   []stateDiff `json:"states"`
+	Diverged bool        `json:"diverged"`
+}
+
+type stateDiff struct {
+	Key    string          `json:"key"`
+	Status string          `json:"status"`
+	Nodes  []stateDiffNode `json:"nodes"`
+}
+
+type stateDiffNode struct {
+	Remote          string          `json:"remote"`
+	Hash            string          `json:"hash,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Value           json.RawMessage `json:"value,omitempty"`
+	Height          base.Height     `json:"height"`
+	Found           bool            `json:"found"`
+	HashMismatched  bool            `json:"hash_mismatched"`
+	ValueMismatched bool            `json:"value_mismatched"`
+}
+
+func (cmd *NetworkClientStateDiffCommand) Run(
+	kctx *kong.Context, pctx context.Context, //revive:disable-line:context-as-argument
+) error {
+	switch cmd.Format {
+	case "table", "json":
+	default:
+		return errors.Errorf("unsupported format, %q", cmd.Format)
+	}
+
+	if cmd.Workers < 1 {
+		return errors.Errorf("workers should be over zero, %d", cmd.Workers)
+	}
+
+	switch diverged, err := cmd.run(pctx); {
+	case err != nil:
+		return err
+	case diverged:
+		kctx.Exit(1)
+	}
+
+	return nil
+}
+
+func (cmd *NetworkClientStateDiffCommand) run(pctx context.Context) (bool, error) {
+	keys, err := cmd.stateKeys()
+	if err != nil {
+		return false, err
+	}
+
+	if err := cmd.Prepare(pctx); err != nil {
+		return false, err
+	}
+
+	defer func() {
+		_ = cmd.Client.Close()
+	}()
+
+	remotes := append([]launch.ConnInfoFlag{cmd.Remote}, cmd.Remotes...)
+
+	report := stateDiffReport{
+		Remotes: make([]string, len(remotes)),
+		States:  make([]stateDiff, len(keys)),
+	}
+
+	for i := range remotes {
+		report.Remotes[i] = remotes[i].String()
+	}
+
+	for i := range keys {
+		report.States[i] = stateDiff{Key: keys[i], Nodes: make([]stateDiffNode, len(remotes))}
+	}
+
+	if err := util.RunJobWorker(pctx, cmd.Workers, int64(len(keys)*len(remotes)),
+		func(ctx context.Context, i, _ uint64) error {
+			k, r := int(i)/len(remotes), int(i)%len(remotes)
+
+			report.States[k].Nodes[r] = cmd.fetchState(ctx, remotes[r], keys[k])
+
+			return nil
+		},
+	); err != nil {
+		return false, err
+	}
+
+	for i := range report.States {
+		compareStateDiff(&report.States[i])
+
+		if report.States[i].Status != stateDiffSame {
+			report.Diverged = true
+		}
+	}
+
+	if err := cmd.printReport(report); err != nil {
+		return false, err
+	}
+
+	return report.Diverged, nil
+}
+
+func (cmd *NetworkClientStateDiffCommand) stateKeys() ([]string, error) {
+	keys := make([]string, 0, len(cmd.Keys))
+
+	for i := range cmd.Keys {
+		if k := strings.TrimSpace(cmd.Keys[i]); len(k) > 0 {
+			keys = append(keys, k)
+		}
+	}
+
+	if len(cmd.KeysFile) > 0 {
+		i, err := readLinesFile(cmd.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, i...)
+	}
+
+	if len(keys) < 1 {
+		return nil, errors.Errorf("empty state keys; --key or --keys-file")
+	}
+
+	return keys, nil
+}
+
+func (cmd *NetworkClientStateDiffCommand) fetchState(
+	pctx context.Context, remote launch.ConnInfoFlag, key string,
+) stateDiffNode {
+	n := stateDiffNode{Remote: remote.String(), Height: base.NilHeight}
+
+	ctx, cancel := context.WithTimeout(pctx, cmd.Timeout)
+	defer cancel()
+
+	switch st, found, err := cmd.Client.State(ctx, remote.ConnInfo(), key, nil); {
+	case err != nil:
+		n.Error = err.Error()
+
+		cmd.Log.Error().Err(err).Str("key", key).Stringer("remote", remote).Msg("failed to get state")
+	case !found:
+	default:
+		n.Found = true
+		n.Hash = st.Hash().String()
+		n.Height = st.Height()
+
+		switch b, err := util.MarshalJSON(st.Value()); {
+		case err != nil:
+			n.Error = err.Error()
+		default:
+			n.Value = b
+		}
+	}
+
+	return n
+}
+
+// compareStateDiff marks the nodes, which disagree with the most nodes on hash
+// or value.
+func compareStateDiff(d *stateDiff) {
+	d.Status = stateDiffSame
+
+	hashes := map[string]int{}
+	values := map[string]int{}
+
+	for i := range d.Nodes {
+		n := d.Nodes[i]
+
+		if len(n.Error) > 0 {
+			d.Status = stateDiffError
+
+			continue
+		}
+
+		hashes[n.Hash]++
+		values[string(n.Value)]++
+	}
+
+	hash, value := mostCommonString(hashes), mostCommonString(values)
+
+	for i := range d.Nodes {
+		n := &d.Nodes[i]
+
+		if len(n.Error) > 0 {
+			continue
+		}
+
+		n.HashMismatched = n.Hash != hash
+		n.ValueMismatched = string(n.Value) != value
+
+		if n.HashMismatched || n.ValueMismatched {
+			d.Status = stateDiffDiverged
+		}
+	}
+}
+
+func mostCommonString(m map[string]int) string {
+	var s string
+	var count int
+
+	for _, k := range sortedStringKeys(m) {
+		if m[k] > count {
+			s, count = k, m[k]
+		}
+	}
+
+	return s
+}
+
+func (cmd *NetworkClientStateDiffCommand) printReport(report stateDiffReport) error {
+	if cmd.Format == "json" {
+		return cmd.Print(report, cmd.Out)
+	}
+
+	w := tabwriter.NewWriter(cmd.Out, 0, 0, 2, ' ', 0) //nolint:gomnd //...
+
+	_, _ = fmt.Fprintln(w, "KEY\tSTATUS\tREMOTE\tFOUND\tHEIGHT\tHASH\tMISMATCHED")
+
+	for i := range report.States {
+		d := report.States[i]
+
+		for j := range d.Nodes {
+			n := d.Nodes[j]
+
+			var mismatched []string
+
+			if n.HashMismatched {
+				mismatched = append(mismatched, "hash")
+			}
+
+			if n.ValueMismatched {
+				mismatched = append(mismatched, "value")
+			}
+
+			if len(n.Error) > 0 {
+				mismatched = append(mismatched, "error: "+n.Error)
+			}
+
+			hash := n.Hash
+			if len(hash) < 1 {
+				hash = "-"
+			}
+
+			height := "-"
+			if n.Found {
+				height = n.Height.String()
+			}
+
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%s\t%s\n",
+				d.Key, d.Status, n.Remote, n.Found, height, hash, strings.Join(mismatched, ","))
+		}
+	}
+
+	return errors.WithStack(w.Flush())
+}
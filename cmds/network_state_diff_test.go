@@ -0,0 +1,84 @@
+package cmds
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompareStateDiff(t *testing.T) {
+	node := func(hash, value string) stateDiffNode {
+		n := stateDiffNode{Hash: hash, Found: len(hash) > 0}
+
+		if len(value) > 0 {
+			n.Value = json.RawMessage(value)
+		}
+
+		return n
+	}
+
+	failed := stateDiffNode{Error: "failed"}
+
+	cases := []struct {
+		name       string
+		nodes      []stateDiffNode
+		status     string
+		mismatched []bool
+	}{
+		{
+			name:       "same",
+			nodes:      []stateDiffNode{node("a", "1"), node("a", "1"), node("a", "1")},
+			status:     stateDiffSame,
+			mismatched: []bool{false, false, false},
+		},
+		{
+			name:       "not found in all",
+			nodes:      []stateDiffNode{node("", ""), node("", "")},
+			status:     stateDiffSame,
+			mismatched: []bool{false, false},
+		},
+		{
+			name:       "minor hash",
+			nodes:      []stateDiffNode{node("a", "1"), node("b", "1"), node("a", "1")},
+			status:     stateDiffDiverged,
+			mismatched: []bool{false, true, false},
+		},
+		{
+			name:       "not found in one",
+			nodes:      []stateDiffNode{node("a", "1"), node("a", "1"), node("", "")},
+			status:     stateDiffDiverged,
+			mismatched: []bool{false, false, true},
+		},
+		{
+			name:       "error",
+			nodes:      []stateDiffNode{node("a", "1"), failed, node("a", "1")},
+			status:     stateDiffError,
+			mismatched: []bool{false, false, false},
+		},
+		{
+			name:       "error and diverged",
+			nodes:      []stateDiffNode{node("a", "1"), failed, node("b", "2"), node("a", "1")},
+			status:     stateDiffDiverged,
+			mismatched: []bool{false, false, true, false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := stateDiff{Key: "k", Nodes: c.nodes}
+
+			compareStateDiff(&d)
+
+			if d.Status != c.status {
+				t.Errorf("status: expected %q, got %q", c.status, d.Status)
+			}
+
+			for i := range d.Nodes {
+				n := d.Nodes[i]
+
+				if mismatched := n.HashMismatched || n.ValueMismatched; mismatched != c.mismatched[i] {
+					t.Errorf("node %d: expected mismatched %v, got %v", i, c.mismatched[i], mismatched)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,426 @@
+package cmds
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	reOperationTemplateVariable = regexp.MustCompile(`\$?\$\{(\w+)\}`)
+	maxOperationLineSize        = 1 << 23 //nolint:gomnd //...
+)
+
+// OperationTemplateFlags is the variables of operation template; the
+// `${NAME}` in the string values of operation is replaced by the variable or
+// the environment variable. `$${NAME}` is kept as `${NAME}`.
+type OperationTemplateFlags struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	Vars     map[string]string `name:"var" help:"template variable, '<name>=<value>'; environment variables are also used" placeholder:"name=value"`
+	VarsFile string            `name:"vars-file" help:"yaml file of template variables" type:"existingfile" placeholder:"file"`
+	Template bool              `name:"template" help:"replace template variables in string values with environment variables; also enabled by --var or --vars-file"`
+	//revive:enable:line-length-limit
+}
+
+// templateVariables returns the variables of flags; the --var overrides the
+// variables of --vars-file. Without template flags, it returns nil and the
+// operation is not templated.
+func (f OperationTemplateFlags) templateVariables() (map[string]string, error) {
+	if !f.Template && len(f.Vars) < 1 && len(f.VarsFile) < 1 {
+		return nil, nil
+	}
+
+	vars := map[string]string{}
+
+	if len(f.VarsFile) > 0 {
+		b, err := os.ReadFile(filepath.Clean(f.VarsFile))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		var u map[string]interface{}
+
+		if err := yaml.Unmarshal(b, &u); err != nil {
+			return nil, errors.WithMessagef(err, "vars file, %q", f.VarsFile)
+		}
+
+		for k := range u {
+			switch t := u[k].(type) {
+			case string:
+				vars[k] = t
+			case nil:
+				vars[k] = ""
+			default:
+				i, err := util.MarshalJSON(t)
+				if err != nil {
+					return nil, err
+				}
+
+				vars[k] = string(i)
+			}
+		}
+	}
+
+	for k := range f.Vars {
+		vars[k] = f.Vars[k]
+	}
+
+	return vars, nil
+}
+
+// replaceTemplateVariables replaces `${NAME}` in the string values of decoded
+// input with the variable or the environment variable; the missing variables
+// are returned as error.
+func replaceTemplateVariables(v interface{}, vars map[string]string) (interface{}, error) {
+	missing := map[string]struct{}{}
+
+	nv := replaceTemplateValue(v, vars, missing)
+
+	if len(missing) > 0 {
+		return nil, errors.Errorf("template variables not found, %q", sortedStringKeys(missing))
+	}
+
+	return nv, nil
+}
+
+func replaceTemplateValue(v interface{}, vars map[string]string, missing map[string]struct{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return replaceTemplateString(t, vars, missing)
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = replaceTemplateValue(t[k], vars, missing)
+		}
+
+		return t
+	case map[interface{}]interface{}:
+		for k := range t {
+			t[k] = replaceTemplateValue(t[k], vars, missing)
+		}
+
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = replaceTemplateValue(t[i], vars, missing)
+		}
+
+		return t
+	default:
+		return v
+	}
+}
+
+func replaceTemplateString(s string, vars map[string]string, missing map[string]struct{}) string {
+	return reOperationTemplateVariable.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+
+		name := match[2 : len(match)-1]
+
+		if v, found := vars[name]; found {
+			return v
+		}
+
+		if v, found := os.LookupEnv(name); found {
+			return v
+		}
+
+		missing[name] = struct{}{}
+
+		return match
+	})
+}
+
+// operationInputToJSON converts the operation input to json. The input is json
+// or yaml with `_hint` fields like genesis design.
+func operationInputToJSON(b []byte, vars map[string]string) ([]byte, error) {
+	l, err := operationInputsToJSON(b, vars)
+
+	switch {
+	case err != nil:
+		return nil, err
+	case len(l) != 1:
+		return nil, errors.Errorf("expected one operation, but %d", len(l))
+	default:
+		return l[0], nil
+	}
+}
+
+// operationInputsToJSON converts the operation input to json; yaml input can
+// have multiple documents. With nil vars, the input is not templated and the
+// json input is returned as it is.
+func operationInputsToJSON(b []byte, vars map[string]string) ([][]byte, error) {
+	if isJSONInput(b) {
+		if vars == nil {
+			return [][]byte{b}, nil
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+
+		var u interface{}
+
+		if err := dec.Decode(&u); err != nil {
+			return nil, errors.WithMessage(err, "json")
+		}
+
+		i, err := templateOperationInput(u, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		return [][]byte{i}, nil
+	}
+
+	var l [][]byte
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+
+	for {
+		var u interface{}
+
+		switch err := dec.Decode(&u); {
+		case errors.Is(err, io.EOF):
+			return l, nil
+		case err != nil:
+			return nil, errors.WithMessage(err, "yaml")
+		case u == nil:
+			continue
+		}
+
+		i, err := templateOperationInput(u, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		l = append(l, i)
+	}
+}
+
+func templateOperationInput(u interface{}, vars map[string]string) ([]byte, error) {
+	if vars != nil {
+		i, err := replaceTemplateVariables(u, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		u = i
+	}
+
+	return util.MarshalJSON(u)
+}
+
+func isJSONInput(b []byte) bool {
+	i := bytes.TrimSpace(b)
+
+	return len(i) > 0 && (i[0] == '{' || i[0] == '[')
+}
+
+func isYAMLFile(f string) bool {
+	switch strings.ToLower(filepath.Ext(f)) {
+	case ".yml", ".yaml":
+		return true
+	default:
+		return false
+	}
+}
+
+func isOperationFile(f string) bool {
+	return isYAMLFile(f) || strings.EqualFold(filepath.Ext(f), ".json")
+}
+
+func sortedOperationFiles(entries []os.DirEntry) []string {
+	var files []string
+
+	for i := range entries {
+		if !entries[i].IsDir() && isOperationFile(entries[i].Name()) {
+			files = append(files, entries[i].Name())
+		}
+	}
+
+	sort.Strings(files)
+
+	return files
+}
+
+// loadOperations loads the operations from input. With bulk, input is the json
+// lines of operations, the yaml of multiple documents or the directory of
+// operation files.
+func loadOperations(
+	enc encoder.Encoder, input string, isString, bulk bool, vars map[string]string,
+) ([]base.Operation, error) {
+	if !bulk {
+		switch i, err := launch.LoadInputFlag(input, !isString); {
+		case err != nil:
+			return nil, err
+		case len(i) < 1:
+			return nil, errors.Errorf("empty input")
+		default:
+			op, err := decodeOperationInput(enc, i, vars)
+			if err != nil {
+				return nil, err
+			}
+
+			return []base.Operation{op}, nil
+		}
+	}
+
+	var ops []base.Operation
+	var err error
+
+	switch {
+	case isString:
+		ops, err = decodeOperationsInput(enc, []byte(input), vars)
+	case input == "-":
+		b, rerr := io.ReadAll(os.Stdin)
+		if rerr != nil {
+			return nil, errors.WithStack(rerr)
+		}
+
+		ops, err = decodeOperationsInput(enc, b, vars)
+	default:
+		ops, err = loadOperationsFromPath(enc, input, vars)
+	}
+
+	switch {
+	case err != nil:
+		return nil, err
+	case len(ops) < 1:
+		return nil, errors.Errorf("empty operations")
+	default:
+		return ops, nil
+	}
+}
+
+func loadOperationsFromPath(enc encoder.Encoder, p string, vars map[string]string) ([]base.Operation, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !fi.IsDir() {
+		b, err := os.ReadFile(filepath.Clean(p))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if isYAMLFile(p) {
+			return decodeOperationDocuments(enc, b, vars)
+		}
+
+		return decodeOperationsInput(enc, b, vars)
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var ops []base.Operation
+
+	for _, f := range sortedOperationFiles(entries) {
+		b, err := os.ReadFile(filepath.Join(p, f))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		l, err := decodeOperationDocuments(enc, b, vars)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "file %q", f)
+		}
+
+		ops = append(ops, l...)
+	}
+
+	return ops, nil
+}
+
+// decodeOperationsInput decodes the json lines or the yaml of multiple
+// documents.
+func decodeOperationsInput(enc encoder.Encoder, b []byte, vars map[string]string) ([]base.Operation, error) {
+	if !isJSONInput(b) {
+		return decodeOperationDocuments(enc, b, vars)
+	}
+
+	var ops []base.Operation
+
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(nil, maxOperationLineSize)
+
+	var line int
+
+	for sc.Scan() {
+		line++
+
+		i := bytes.TrimSpace(sc.Bytes())
+		if len(i) < 1 {
+			continue
+		}
+
+		op, err := decodeOperationInput(enc, i, vars)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "line %d", line)
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, errors.WithStack(sc.Err())
+}
+
+func decodeOperationDocuments(enc encoder.Encoder, b []byte, vars map[string]string) ([]base.Operation, error) {
+	l, err := operationInputsToJSON(b, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make([]base.Operation, len(l))
+
+	for i := range l {
+		op, err := decodeOperation(enc, l[i])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "document %d", i)
+		}
+
+		ops[i] = op
+	}
+
+	return ops, nil
+}
+
+func decodeOperationInput(enc encoder.Encoder, b []byte, vars map[string]string) (base.Operation, error) {
+	i, err := operationInputToJSON(b, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeOperation(enc, i)
+}
+
+func decodeOperation(enc encoder.Encoder, b []byte) (base.Operation, error) {
+	var op base.Operation
+
+	if err := encoder.Decode(enc, b, &op); err != nil {
+		return nil, err
+	}
+
+	if op == nil {
+		return nil, errors.Errorf("empty operation")
+	}
+
+	return op, nil
+}
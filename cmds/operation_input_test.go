@@ -0,0 +1,117 @@
+package cmds
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestReplaceTemplateVariables(t *testing.T) {
+	t.Setenv("IMFACT_TEMPLATE_ENV", "env")
+
+	vars := map[string]string{"a": "A", "b": "B"}
+
+	cases := []struct {
+		name     string
+		input    string
+		vars     map[string]string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "string values",
+			input:    `{"k": "${a}", "l": ["x-${b}", 1], "m": {"n": "${a}${b}"}}`,
+			vars:     vars,
+			expected: `{"k": "A", "l": ["x-B", 1], "m": {"n": "AB"}}`,
+		},
+		{
+			name:     "keys not replaced",
+			input:    `{"${a}": "${a}"}`,
+			vars:     vars,
+			expected: `{"${a}": "A"}`,
+		},
+		{
+			name:     "escape",
+			input:    `{"k": "$${a}", "l": "$${unknown}"}`,
+			vars:     vars,
+			expected: `{"k": "${a}", "l": "${unknown}"}`,
+		},
+		{
+			name:     "environment",
+			input:    `{"k": "${IMFACT_TEMPLATE_ENV}"}`,
+			vars:     map[string]string{},
+			expected: `{"k": "env"}`,
+		},
+		{
+			name:     "value not templated again",
+			input:    `{"k": "${a}"}`,
+			vars:     map[string]string{"a": "${b}"},
+			expected: `{"k": "${b}"}`,
+		},
+		{
+			name:  "missing",
+			input: `{"k": "${a}", "l": "${unknown}"}`,
+			vars:  vars,
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var u interface{}
+
+			if err := json.Unmarshal([]byte(c.input), &u); err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := replaceTemplateVariables(u, c.vars)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			var expected interface{}
+
+			if err := json.Unmarshal([]byte(c.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(v, expected) {
+				t.Errorf("expected %v, got %v", expected, v)
+			}
+		})
+	}
+}
+
+func TestOperationInputsToJSONWithoutTemplate(t *testing.T) {
+	input := []byte(`{"memo": "${a}", "amount": 12345678901234567890}`)
+
+	l, err := operationInputsToJSON(input, nil)
+
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %+v", err)
+	case len(l) != 1:
+		t.Fatalf("expected one, got %d", len(l))
+	case string(l[0]) != string(input):
+		t.Errorf("input changed, %q", l[0])
+	}
+
+	l, err = operationInputsToJSON(input, map[string]string{"a": "A"})
+
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %+v", err)
+	case len(l) != 1:
+		t.Fatalf("expected one, got %d", len(l))
+	case string(l[0]) != `{"amount":12345678901234567890,"memo":"A"}`:
+		t.Errorf("unexpected json, %q", l[0])
+	}
+}
@@ -0,0 +1,322 @@
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/valuehash"
+	"github.com/pkg/errors"
+)
+
+type OperationSignCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseCommand
+	OperationTemplateFlags
+	NetworkID       string             `arg:"" name:"network-id" help:"network-id"`
+	Input           string             `arg:"" name:"input" help:"operation, json or yaml; default is stdin" default:"-"`
+	IsString        bool               `name:"input.is-string" help:"input is string, not file"`
+	Privatekeys     []string           `name:"privatekey" help:"privatekey string to sign; multiple keys are allowed" placeholder:"privatekey"`
+	PrivatekeysFile string             `name:"privatekeys-file" help:"file of privatekeys, one key in one line" type:"existingfile"`
+	Merge           []string           `name:"merge" help:"partially signed operation file; the signs are merged" type:"existingfile" placeholder:"file"`
+	Node            launch.AddressFlag `name:"node" help:"node address for node operation"`
+	Output          string             `name:"output" help:"output file; default is stdout" placeholder:"file"`
+	//revive:enable:line-length-limit
+	privs     []base.Privatekey
+	vars      map[string]string
+	networkID base.NetworkID
+}
+
+// operationHashSetter is the operation, whose hash can be updated after the
+// signs are changed.
+type operationHashSetter interface {
+	HashBytes() []byte
+	SetHash(util.Hash)
+}
+
+type signedOperationJSON struct {
+	op    base.Operation
+	raw   map[string]json.RawMessage
+	signs []json.RawMessage
+}
+
+func (cmd *OperationSignCommand) Run(pctx context.Context) error {
+	if err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	input, err := cmd.loadInput()
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.Merge) > 0 {
+		i, err := cmd.merge(input)
+		if err != nil {
+			return err
+		}
+
+		input = i
+	}
+
+	ptr, err := operationPointer(input.op)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.sign(ptr); err != nil {
+		return err
+	}
+
+	op := ptr.(base.Operation) //nolint:forcetypeassert //...
+
+	if err := op.IsValid(cmd.networkID); err != nil {
+		return err
+	}
+
+	cmd.Log.Debug().
+		Interface("hash", op.Hash()).
+		Int("signs", len(op.Signs())).
+		Msg("signed")
+
+	b, err := util.MarshalJSONIndent(op)
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.Output) < 1 {
+		_, err = fmt.Fprintln(cmd.Out, string(b))
+
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(filepath.Clean(cmd.Output), append(b, '\n'), 0o600))
+}
+
+func (cmd *OperationSignCommand) prepare(pctx context.Context) error {
+	if _, err := cmd.BaseCommand.prepare(pctx); err != nil {
+		return err
+	}
+
+	cmd.networkID = base.NetworkID([]byte(cmd.NetworkID))
+
+	if err := cmd.networkID.IsValid(nil); err != nil {
+		return err
+	}
+
+	switch i, err := cmd.templateVariables(); {
+	case err != nil:
+		return err
+	default:
+		cmd.vars = i
+	}
+
+	keys := cmd.Privatekeys
+
+	if len(cmd.PrivatekeysFile) > 0 {
+		i, err := readLinesFile(cmd.PrivatekeysFile)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, i...)
+	}
+
+	if len(keys) < 1 && len(cmd.Merge) < 1 {
+		return errors.Errorf("empty privatekeys; --privatekey, --privatekeys-file or --merge")
+	}
+
+	cmd.privs = make([]base.Privatekey, len(keys))
+
+	for i := range keys {
+		switch key, err := base.DecodePrivatekeyFromString(keys[i], cmd.Encoder); {
+		case err != nil:
+			return errors.WithMessagef(err, "privatekey #%d", i)
+		default:
+			if err := key.IsValid(nil); err != nil {
+				return errors.WithMessagef(err, "privatekey #%d", i)
+			}
+
+			cmd.privs[i] = key
+		}
+	}
+
+	return nil
+}
+
+func (cmd *OperationSignCommand) loadInput() (signedOperationJSON, error) {
+	switch i, err := launch.LoadInputFlag(cmd.Input, !cmd.IsString); {
+	case err != nil:
+		return signedOperationJSON{}, err
+	case len(i) < 1:
+		return signedOperationJSON{}, errors.Errorf("empty input")
+	default:
+		return cmd.decode(i)
+	}
+}
+
+func (cmd *OperationSignCommand) decode(input []byte) (signedOperationJSON, error) {
+	var s signedOperationJSON
+
+	b, err := operationInputToJSON(input, cmd.vars)
+	if err != nil {
+		return s, err
+	}
+
+	op, err := decodeOperation(cmd.Encoder, b)
+	if err != nil {
+		return s, err
+	}
+
+	s.op = op
+
+	if err := util.UnmarshalJSON(b, &s.raw); err != nil {
+		return s, err
+	}
+
+	if i, found := s.raw["signs"]; found {
+		if err := util.UnmarshalJSON(i, &s.signs); err != nil {
+			return s, err
+		}
+	}
+
+	if len(s.signs) != len(op.Signs()) {
+		return s, errors.Errorf("signs not matched; %d != %d", len(s.signs), len(op.Signs()))
+	}
+
+	return s, nil
+}
+
+// merge adds the signs of partially signed operations to input; the signs of
+// same signer are added only once.
+func (cmd *OperationSignCommand) merge(input signedOperationJSON) (signedOperationJSON, error) {
+	e := util.StringError("merge signs")
+
+	fact := input.op.Fact()
+	if fact == nil || fact.Hash() == nil {
+		return input, e.Errorf("empty fact")
+	}
+
+	signers := map[string]struct{}{}
+
+	for _, s := range input.op.Signs() {
+		signers[s.Signer().String()] = struct{}{}
+	}
+
+	signs := input.signs
+
+	for i := range cmd.Merge {
+		b, err := os.ReadFile(filepath.Clean(cmd.Merge[i]))
+		if err != nil {
+			return input, e.Wrap(errors.WithStack(err))
+		}
+
+		m, err := cmd.decode(b)
+		if err != nil {
+			return input, e.WithMessage(err, "file %q", cmd.Merge[i])
+		}
+
+		switch mfact := m.op.Fact(); {
+		case mfact == nil || mfact.Hash() == nil:
+			return input, e.Errorf("empty fact, file %q", cmd.Merge[i])
+		case !mfact.Hash().Equal(fact.Hash()):
+			return input, e.Errorf("different fact hash, file %q; %q != %q", cmd.Merge[i], mfact.Hash(), fact.Hash())
+		}
+
+		for j, s := range m.op.Signs() {
+			if _, found := signers[s.Signer().String()]; found {
+				continue
+			}
+
+			if err := s.Verify(cmd.networkID, fact.Hash().Bytes()); err != nil {
+				return input, e.WithMessage(err, "sign of %q, file %q", s.Signer(), cmd.Merge[i])
+			}
+
+			signers[s.Signer().String()] = struct{}{}
+			signs = append(signs, m.signs[j])
+		}
+	}
+
+	b, err := util.MarshalJSON(signs)
+	if err != nil {
+		return input, e.Wrap(err)
+	}
+
+	input.raw["signs"] = b
+
+	switch i, err := util.MarshalJSON(input.raw); {
+	case err != nil:
+		return input, e.Wrap(err)
+	default:
+		merged, err := cmd.decode(i)
+		if err != nil {
+			return input, e.Wrap(err)
+		}
+
+		ptr, err := operationPointer(merged.op)
+		if err != nil {
+			return input, e.Wrap(err)
+		}
+
+		hs, ok := ptr.(operationHashSetter)
+		if !ok {
+			return input, e.Errorf("hash of operation can not be updated, %T", merged.op)
+		}
+
+		hs.SetHash(valuehash.NewSHA256(hs.HashBytes()))
+
+		merged.op = ptr.(base.Operation) //nolint:forcetypeassert //...
+
+		return merged, nil
+	}
+}
+
+func (cmd *OperationSignCommand) sign(ptr interface{}) error {
+	for i := range cmd.privs {
+		priv := cmd.privs[i]
+
+		switch t := ptr.(type) {
+		case base.NodeSigner:
+			if cmd.Node.Address() == nil {
+				return errors.Errorf("--node is missing")
+			}
+
+			if err := t.NodeSign(priv, cmd.networkID, cmd.Node.Address()); err != nil {
+				return err
+			}
+		case base.Signer:
+			if err := t.Sign(priv, cmd.networkID); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("not Signer, %T", ptr)
+		}
+	}
+
+	return nil
+}
+
+// operationPointer returns the pointer of operation to sign it.
+func operationPointer(op base.Operation) (interface{}, error) {
+	if reflect.ValueOf(op).Kind() == reflect.Ptr {
+		return op, nil
+	}
+
+	ptr := reflect.New(reflect.ValueOf(op).Type()).Interface()
+
+	if err := util.ReflectSetInterfaceValue(op, ptr); err != nil {
+		return nil, err
+	}
+
+	if _, ok := ptr.(base.Operation); !ok {
+		return nil, errors.Errorf("not operation, %T", ptr)
+	}
+
+	return ptr, nil
+}
@@ -0,0 +1,382 @@
+package cmds
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	isaacblock "github.com/imfact-labs/mitum2/isaac/block"
+	isaacnetwork "github.com/imfact-labs/mitum2/isaac/network"
+	"github.com/imfact-labs/mitum2/network/quicstream"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/imfact-labs/mitum2/util/fixedtree"
+	"github.com/pkg/errors"
+)
+
+const (
+	operationStatusAccepted = "accepted"
+	operationStatusRejected = "rejected"
+	operationStatusTimeout  = "timeout"
+)
+
+// handlerPathDigestOperation is the digest api path of operation by fact hash.
+var handlerPathDigestOperation = "/block/operation/"
+
+type operationResult struct {
+	FactHash string      `json:"fact_hash"`
+	Stage    string      `json:"stage,omitempty"`
+	Status   string      `json:"status"`
+	Reason   string      `json:"reason,omitempty"`
+	Remote   string      `json:"remote,omitempty"`
+	Height   base.Height `json:"height,omitempty"`
+	InState  bool        `json:"in_state"`
+}
+
+// operationTracker finds the block, which includes operation. Without digest
+// api, it reads the operations tree of the new blocks from remote node.
+type operationTracker struct {
+	client  *isaacnetwork.BaseClient
+	readers *isaac.BlockItemReaders
+	remotes isaac.RemotesBlockItemReadFunc
+	ci      quicstream.ConnInfo
+	api     string
+	temp    string
+	timeout time.Duration
+	checked base.Height
+}
+
+func newOperationTracker(
+	encs *encoder.Encoders,
+	client *isaacnetwork.BaseClient,
+	ci quicstream.ConnInfo,
+	api string,
+	timeout time.Duration,
+) (*operationTracker, error) {
+	t := &operationTracker{
+		client:  client,
+		ci:      ci,
+		api:     strings.TrimRight(api, "/"),
+		timeout: timeout,
+		checked: base.NilHeight,
+		remotes: isaac.NewDefaultRemotesBlockItemReadFunc(),
+	}
+
+	if len(t.api) > 0 {
+		return t, nil
+	}
+
+	// NOTE the readers only decode the items from remote; the root is not used.
+	temp, err := os.MkdirTemp("", "mitum-operation-tracker-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	args := isaac.NewBlockItemReadersArgs()
+	args.DecompressReaderFunc = util.DefaultDecompressReaderFunc
+
+	t.temp = temp
+	t.readers = isaac.NewBlockItemReaders(temp, encs, args)
+	_ = t.readers.Add(isaacblock.LocalFSWriterHint, isaacblock.NewDefaultItemReaderFunc(1)) //nolint:gomnd //...
+
+	return t, nil
+}
+
+func (t *operationTracker) close() {
+	if t.readers != nil {
+		t.readers.Close()
+	}
+
+	if len(t.temp) > 0 {
+		_ = os.RemoveAll(t.temp)
+	}
+}
+
+// start keeps the current last height of remote node; the blocks after the
+// height are checked.
+func (t *operationTracker) start(ctx context.Context) error {
+	if len(t.api) > 0 {
+		return nil
+	}
+
+	switch i, err := t.lastHeight(ctx); {
+	case err != nil:
+		return err
+	default:
+		t.checked = i
+
+		return nil
+	}
+}
+
+// wait polls until all the operations are found or timeout; the not found
+// operations are returned as timeout.
+func (t *operationTracker) wait(
+	ctx context.Context,
+	facthashes []util.Hash,
+	interval, timeout time.Duration,
+	f func(operationResult) error,
+) error {
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	left := map[string]util.Hash{}
+
+	for i := range facthashes {
+		left[facthashes[i].String()] = facthashes[i]
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for len(left) > 0 {
+		select {
+		case <-wctx.Done():
+			for _, k := range sortedStringKeys(left) {
+				if err := f(operationResult{FactHash: k, Status: operationStatusTimeout}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		case <-ticker.C:
+		}
+
+		found, err := t.poll(wctx, left)
+
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			continue
+		case err != nil:
+			return err
+		}
+
+		for i := range found {
+			delete(left, found[i].FactHash)
+
+			if err := f(found[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *operationTracker) poll(ctx context.Context, facthashes map[string]util.Hash) ([]operationResult, error) {
+	if len(t.api) > 0 {
+		var found []operationResult
+
+		for _, k := range sortedStringKeys(facthashes) {
+			switch r, ok, err := t.fromDigest(ctx, facthashes[k]); {
+			case err != nil:
+				return found, err
+			case ok:
+				found = append(found, r)
+			}
+		}
+
+		return found, nil
+	}
+
+	last, err := t.lastHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []operationResult
+
+	for height := t.checked + 1; height <= last; height++ {
+		l, err := t.fromBlock(ctx, height, facthashes)
+		if err != nil {
+			return found, err
+		}
+
+		found = append(found, l...)
+
+		t.checked = height
+	}
+
+	return found, nil
+}
+
+func (t *operationTracker) lastHeight(pctx context.Context) (base.Height, error) {
+	ctx, cancel := context.WithTimeout(pctx, t.timeout)
+	defer cancel()
+
+	switch bm, found, err := t.client.LastBlockMap(ctx, t.ci, nil); {
+	case err != nil:
+		return base.NilHeight, err
+	case !found:
+		return base.NilHeight, nil
+	default:
+		return bm.Manifest().Height(), nil
+	}
+}
+
+func (t *operationTracker) fromBlock(
+	pctx context.Context,
+	height base.Height,
+	facthashes map[string]util.Hash,
+) ([]operationResult, error) {
+	ctx, cancel := context.WithTimeout(pctx, t.timeout)
+	defer cancel()
+
+	var tr fixedtree.Tree
+
+	decode := func(r io.Reader, compressFormat string) error {
+		i, err := isaac.BlockItemReadersDecodeFromReader[fixedtree.Tree](
+			t.readers.ItemFromReader, base.BlockItemOperationsTree, r, compressFormat, nil)
+		if err != nil {
+			return err
+		}
+
+		tr = i
+
+		return nil
+	}
+
+	switch found, err := t.client.BlockItem(ctx, t.ci, height, base.BlockItemOperationsTree,
+		func(r io.Reader, uri url.URL, compressFormat string) error {
+			if r != nil {
+				return decode(r, compressFormat)
+			}
+
+			switch known, found, err := t.remotes(ctx, uri, compressFormat, decode); {
+			case err != nil:
+				return err
+			case !known, !found:
+				return util.ErrNotFound.Errorf("operations tree, %v", uri)
+			default:
+				return nil
+			}
+		},
+	); {
+	case err != nil:
+		return nil, err
+	case !found:
+		// NOTE block without operations.
+		return nil, nil
+	}
+
+	var found []operationResult
+
+	if err := tr.Traverse(func(_ uint64, node fixedtree.Node) (bool, error) {
+		h, instate := base.ParseTreeNodeOperationKey(node.Key())
+		if h == nil {
+			return true, nil
+		}
+
+		if _, ok := facthashes[h.String()]; !ok {
+			return true, nil
+		}
+
+		r := operationResult{
+			FactHash: h.String(),
+			Status:   operationStatusRejected,
+			Height:   height,
+			InState:  instate,
+		}
+
+		if instate {
+			r.Status = operationStatusAccepted
+		}
+
+		if no, ok := node.(base.OperationFixedtreeNode); ok && no.Reason() != nil {
+			r.Reason = no.Reason().Error()
+		}
+
+		found = append(found, r)
+
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (t *operationTracker) fromDigest(pctx context.Context, facthash util.Hash) (operationResult, bool, error) {
+	r := operationResult{FactHash: facthash.String()}
+
+	ctx, cancel := context.WithTimeout(pctx, t.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.api+handlerPathDigestOperation+facthash.String(), nil)
+	if err != nil {
+		return r, false, errors.WithStack(err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return r, false, errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return r, false, nil
+	case res.StatusCode != http.StatusOK:
+		return r, false, errors.Errorf("unexpected status, %q", res.Status)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return r, false, errors.WithStack(err)
+	}
+
+	var u struct {
+		Embedded struct {
+			Reason  string      `json:"reason"`
+			Height  base.Height `json:"height"`
+			InState bool        `json:"in_state"`
+		} `json:"_embedded"`
+	}
+
+	if err := util.UnmarshalJSON(b, &u); err != nil {
+		return r, false, err
+	}
+
+	r.Height = u.Embedded.Height
+	r.InState = u.Embedded.InState
+	r.Reason = u.Embedded.Reason
+	r.Status = operationStatusRejected
+
+	if r.InState {
+		r.Status = operationStatusAccepted
+	}
+
+	return r, true, nil
+}
+
+// operationResultsExitCode returns the exit code by the worst result; 1 for
+// rejected and 2 for timeout.
+func operationResultsExitCode(results []operationResult) int {
+	var code int
+
+	for i := range results {
+		var c int
+
+		switch results[i].Status {
+		case operationStatusRejected:
+			c = 1
+		case operationStatusTimeout:
+			c = 2 //nolint:gomnd //...
+		}
+
+		if c > code {
+			code = c
+		}
+	}
+
+	return code
+}
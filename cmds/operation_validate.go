@@ -0,0 +1,247 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/alecthomas/kong"
+	"github.com/imfact-labs/imfact-model/runtime/steps"
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/hint"
+	"github.com/pkg/errors"
+)
+
+const (
+	operationCheckIsValid  = "is-valid"
+	operationCheckFactHint = "fact-hint"
+	operationCheckSign     = "sign"
+)
+
+type OperationValidateCommand struct { //nolint:govet //...
+	//revive:disable:line-length-limit
+	BaseCommand
+	OperationTemplateFlags
+	NetworkID string `arg:"" name:"network-id" help:"network-id"`
+	Input     string `arg:"" name:"input" help:"input, json or yaml; default is stdin" default:"-"`
+	IsString  bool   `name:"input.is-string" help:"input is string, not file"`
+	Bulk      bool   `name:"bulk" help:"input is json lines of operations or directory of operation files"`
+	Format    string `name:"format" help:"output format, {text, json}" default:"text"`
+	//revive:enable:line-length-limit
+}
+
+type operationValidation struct {
+	Hash     string           `json:"hash,omitempty"`
+	FactHash string           `json:"fact_hash,omitempty"`
+	Hint     string           `json:"hint,omitempty"`
+	FactHint string           `json:"fact_hint,omitempty"`
+	Checks   []operationCheck `json:"checks"`
+	Valid    bool             `json:"valid"`
+}
+
+type operationCheck struct {
+	Name   string `json:"name"`
+	Signer string `json:"signer,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Passed bool   `json:"passed"`
+}
+
+func (cmd *OperationValidateCommand) Run(
+	kctx *kong.Context, pctx context.Context, //revive:disable-line:context-as-argument
+) error {
+	switch cmd.Format {
+	case "text", "json":
+	default:
+		return errors.Errorf("unsupported format, %q", cmd.Format)
+	}
+
+	if len(cmd.NetworkID) < 1 {
+		return errors.Errorf(`expected "<network-id>"`)
+	}
+
+	if _, err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	vars, err := cmd.templateVariables()
+	if err != nil {
+		return err
+	}
+
+	ops, err := loadOperations(cmd.Encoder, cmd.Input, cmd.IsString, cmd.Bulk, vars)
+	if err != nil {
+		return err
+	}
+
+	vs := validateOperations(ops, base.NetworkID([]byte(cmd.NetworkID)))
+
+	if err := printOperationValidations(cmd.Out, cmd.Format, vs); err != nil {
+		return err
+	}
+
+	if !isValidOperations(vs) {
+		kctx.Exit(1)
+	}
+
+	return nil
+}
+
+func validateOperations(ops []base.Operation, networkID base.NetworkID) []operationValidation {
+	vs := make([]operationValidation, len(ops))
+
+	for i := range ops {
+		vs[i] = validateOperation(ops[i], networkID)
+	}
+
+	return vs
+}
+
+// validateOperation checks the operation like the node does before accepting
+// it; all the checks are done and each failure is kept.
+func validateOperation(op base.Operation, networkID base.NetworkID) operationValidation {
+	v := operationValidation{Valid: true}
+
+	add := func(c operationCheck, err error) {
+		c.Passed = err == nil
+
+		if err != nil {
+			c.Error = err.Error()
+			v.Valid = false
+		}
+
+		v.Checks = append(v.Checks, c)
+	}
+
+	if op.Hash() != nil {
+		v.Hash = op.Hash().String()
+	}
+
+	v.Hint = op.Hint().String()
+
+	add(operationCheck{Name: operationCheckIsValid}, op.IsValid(networkID))
+
+	fact := op.Fact()
+	if fact == nil {
+		add(operationCheck{Name: operationCheckFactHint}, errors.Errorf("empty fact"))
+
+		return v
+	}
+
+	if fact.Hash() != nil {
+		v.FactHash = fact.Hash().String()
+	}
+
+	switch ht, ok := fact.(hint.Hinter); {
+	case !ok:
+		add(operationCheck{Name: operationCheckFactHint}, errors.Errorf("fact does not have hint, %T", fact))
+	default:
+		v.FactHint = ht.Hint().String()
+
+		var err error
+		if !steps.IsSupportedProposalOperationFactHintFunc()(ht.Hint()) {
+			err = errors.Errorf("not supported fact hint, %q", ht.Hint())
+		}
+
+		add(operationCheck{Name: operationCheckFactHint}, err)
+	}
+
+	signs := op.Signs()
+	if len(signs) < 1 {
+		add(operationCheck{Name: operationCheckSign}, errors.Errorf("empty signs"))
+
+		return v
+	}
+
+	for i := range signs {
+		c := operationCheck{Name: operationCheckSign}
+
+		if signs[i].Signer() != nil {
+			c.Signer = signs[i].Signer().String()
+		}
+
+		switch {
+		case fact.Hash() == nil:
+			add(c, errors.Errorf("empty fact hash"))
+		default:
+			add(c, signs[i].Verify(networkID, fact.Hash().Bytes()))
+		}
+	}
+
+	return v
+}
+
+func isValidOperations(vs []operationValidation) bool {
+	for i := range vs {
+		if !vs[i].Valid {
+			return false
+		}
+	}
+
+	return true
+}
+
+func invalidOperationValidations(vs []operationValidation) []operationValidation {
+	var invalids []operationValidation
+
+	for i := range vs {
+		if !vs[i].Valid {
+			invalids = append(invalids, vs[i])
+		}
+	}
+
+	return invalids
+}
+
+func printOperationValidations(w io.Writer, format string, vs []operationValidation) error {
+	if format == "json" {
+		for i := range vs {
+			b, err := util.MarshalJSON(vs[i])
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintln(w, string(b)); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		return nil
+	}
+
+	for i := range vs {
+		v := vs[i]
+
+		status := "valid"
+		if !v.Valid {
+			status = "invalid"
+		}
+
+		_, _ = fmt.Fprintf(w, "operation: %s (%s)\n", v.Hash, status)
+		_, _ = fmt.Fprintf(w, "  hint: %s\n", v.Hint)
+		_, _ = fmt.Fprintf(w, "  fact: %s (%s)\n", v.FactHash, v.FactHint)
+
+		for j := range v.Checks {
+			c := v.Checks[j]
+
+			mark := "ok"
+			if !c.Passed {
+				mark = "fail"
+			}
+
+			name := c.Name
+			if len(c.Signer) > 0 {
+				name += " " + c.Signer
+			}
+
+			switch {
+			case c.Passed:
+				_, _ = fmt.Fprintf(w, "  - [%s] %s\n", mark, name)
+			default:
+				_, _ = fmt.Fprintf(w, "  - [%s] %s: %s\n", mark, name, c.Error)
+			}
+		}
+	}
+
+	return nil
+}
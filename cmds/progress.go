@@ -0,0 +1,328 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/localtime"
+	"github.com/pkg/errors"
+)
+
+type ProgressFlags struct {
+	//revive:disable:line-length-limit
+	Interval time.Duration `name:"progress-interval" help:"interval to print progress; 0 disables progress" default:"0s" placeholder:"duration"`
+	Format   string        `name:"progress-format" help:"progress output format, {text, json}" default:"text"`
+	//revive:enable:line-length-limit
+}
+
+func (f ProgressFlags) IsValid() error {
+	switch f.Format {
+	case "text", "json":
+	default:
+		return errors.Errorf("unsupported progress format, %q", f.Format)
+	}
+
+	if f.Interval < 0 {
+		return errors.Errorf("negative progress interval")
+	}
+
+	return nil
+}
+
+// blockProgress periodically prints the progress of the blocks processing to
+// stderr, so the progress does not mix with the output of command. nil
+// blockProgress is valid and does nothing.
+type blockProgress struct {
+	started     time.Time
+	out         io.Writer
+	cancel      func()
+	name        string
+	format      string
+	wg          sync.WaitGroup
+	total       int64
+	from        base.Height
+	to          base.Height
+	done        atomic.Int64
+	height      atomic.Int64
+	localBytes  atomic.Uint64
+	remoteBytes atomic.Uint64
+	interval    time.Duration
+	printl      sync.Mutex
+}
+
+type blockProgressEvent struct {
+	Time            time.Time     `json:"t"`
+	Name            string        `json:"name"`
+	From            base.Height   `json:"from"`
+	To              base.Height   `json:"to"`
+	Height          base.Height   `json:"height"`
+	Done            int64         `json:"done"`
+	Total           int64         `json:"total"`
+	BlocksPerSecond float64       `json:"blocks_per_second"`
+	ElapsedSeconds  float64       `json:"elapsed_seconds"`
+	ETASeconds      float64       `json:"eta_seconds"`
+	Elapsed         time.Duration `json:"-"`
+	ETA             time.Duration `json:"-"`
+	LocalBytes      uint64        `json:"local_bytes"`
+	RemoteBytes     uint64        `json:"remote_bytes"`
+	Finished        bool          `json:"finished"`
+}
+
+func newBlockProgress(flags ProgressFlags, name string, from, to base.Height) *blockProgress {
+	if flags.Interval < 1 || to < from {
+		return nil
+	}
+
+	p := &blockProgress{
+		out:      os.Stderr,
+		name:     name,
+		format:   flags.Format,
+		from:     from,
+		to:       to,
+		total:    (to - from).Int64() + 1,
+		interval: flags.Interval,
+	}
+
+	p.height.Store(base.NilHeight.Int64())
+
+	return p
+}
+
+func (p *blockProgress) start(ctx context.Context) {
+	if p == nil {
+		return
+	}
+
+	p.started = localtime.Now()
+
+	cctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-cctx.Done():
+				return
+			case <-ticker.C:
+				p.print(false)
+			}
+		}
+	}()
+}
+
+// stop stops the periodic printing and prints the last progress.
+func (p *blockProgress) stop() {
+	if p == nil || p.cancel == nil {
+		return
+	}
+
+	p.cancel()
+	p.wg.Wait()
+
+	p.print(true)
+}
+
+func (p *blockProgress) blockDone(height base.Height) {
+	if p == nil {
+		return
+	}
+
+	p.done.Add(1)
+
+	for {
+		prev := p.height.Load()
+		if height.Int64() <= prev || p.height.CompareAndSwap(prev, height.Int64()) {
+			return
+		}
+	}
+}
+
+// blocksDone marks the blocks until height are done at once.
+func (p *blockProgress) blocksDone(height base.Height, count int64) {
+	if p == nil {
+		return
+	}
+
+	p.done.Add(count - 1)
+	p.blockDone(height)
+}
+
+func (p *blockProgress) addBytes(local bool, n uint64) { //revive:disable-line:flag-parameter
+	switch {
+	case p == nil:
+	case local:
+		p.localBytes.Add(n)
+	default:
+		p.remoteBytes.Add(n)
+	}
+}
+
+// itemFunc counts the bytes read from the item files; the bytes are counted as
+// local or remote by the uri of item file.
+func (p *blockProgress) itemFunc(itemf isaac.BlockItemReadersItemFunc) isaac.BlockItemReadersItemFunc {
+	if p == nil {
+		return itemf
+	}
+
+	return func(
+		height base.Height, t base.BlockItemType, f isaac.BlockItemReaderCallbackFunc,
+	) (base.BlockItemFile, bool, error) {
+		var w progressCountWriter
+
+		itemfile, found, err := itemf(height, t, func(ir isaac.BlockItemReader) error {
+			if _, err := ir.Reader().Tee(&w, nil); err != nil {
+				return err
+			}
+
+			return f(ir)
+		})
+
+		if itemfile != nil {
+			p.addBytes(isaac.IsInLocalBlockItemFile(itemfile.URI()), w.n)
+		}
+
+		return itemfile, found, err
+	}
+}
+
+// byteCounter returns writer, which counts the written bytes as local or
+// remote bytes.
+func (p *blockProgress) byteCounter(local bool) io.Writer { //revive:disable-line:flag-parameter
+	return progressWriterFunc(func(b []byte) (int, error) {
+		p.addBytes(local, uint64(len(b)))
+
+		return len(b), nil
+	})
+}
+
+// remoteReader counts the bytes read from remote item file.
+func (p *blockProgress) remoteReader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+
+	return io.TeeReader(r, p.byteCounter(false))
+}
+
+func (p *blockProgress) event(finished bool) blockProgressEvent { //revive:disable-line:flag-parameter
+	now := localtime.Now()
+	elapsed := now.Sub(p.started)
+	done := p.done.Load()
+
+	ev := blockProgressEvent{
+		Time:        now.UTC(),
+		Name:        p.name,
+		From:        p.from,
+		To:          p.to,
+		Height:      base.Height(p.height.Load()),
+		Done:        done,
+		Total:       p.total,
+		Elapsed:     elapsed,
+		LocalBytes:  p.localBytes.Load(),
+		RemoteBytes: p.remoteBytes.Load(),
+		Finished:    finished,
+	}
+
+	if elapsed > 0 {
+		ev.BlocksPerSecond = float64(done) / elapsed.Seconds()
+	}
+
+	if ev.BlocksPerSecond > 0 && done < p.total {
+		ev.ETA = time.Duration(float64(p.total-done) / ev.BlocksPerSecond * float64(time.Second))
+	}
+
+	ev.ElapsedSeconds = ev.Elapsed.Seconds()
+	ev.ETASeconds = ev.ETA.Seconds()
+
+	return ev
+}
+
+func (p *blockProgress) print(finished bool) { //revive:disable-line:flag-parameter
+	ev := p.event(finished)
+
+	var s string
+
+	switch p.format {
+	case "json":
+		b, err := util.MarshalJSON(ev)
+		if err != nil {
+			return
+		}
+
+		s = string(b)
+	default:
+		var percent float64
+		if ev.Total > 0 {
+			percent = float64(ev.Done) / float64(ev.Total) * 100 //nolint:gomnd //...
+		}
+
+		s = fmt.Sprintf("%s: height=%d done=%d/%d (%.1f%%) blocks/s=%.2f elapsed=%s eta=%s local=%s remote=%s",
+			ev.Name,
+			ev.Height,
+			ev.Done, ev.Total,
+			percent,
+			ev.BlocksPerSecond,
+			ev.Elapsed.Truncate(time.Second),
+			ev.ETA.Truncate(time.Second),
+			humanBytes(ev.LocalBytes),
+			humanBytes(ev.RemoteBytes),
+		)
+
+		if finished {
+			s += " finished"
+		}
+	}
+
+	p.printl.Lock()
+	defer p.printl.Unlock()
+
+	_, _ = fmt.Fprintln(p.out, s)
+}
+
+type progressCountWriter struct {
+	n uint64
+}
+
+func (w *progressCountWriter) Write(b []byte) (int, error) {
+	w.n += uint64(len(b))
+
+	return len(b), nil
+}
+
+type progressWriterFunc func([]byte) (int, error)
+
+func (f progressWriterFunc) Write(b []byte) (int, error) {
+	return f(b)
+}
+
+func humanBytes(n uint64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := uint64(unit), 0
+
+	for i := n / unit; i >= unit; i /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,399 @@
+package cmds
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imfact-labs/mitum2/isaac"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/localtime"
+	"github.com/imfact-labs/mitum2/util/valuehash"
+	"github.com/pkg/errors"
+)
+
+var (
+	remoteItemCacheTempPrefix = ".tmp-"
+	remoteItemCacheStaleTemp  = time.Hour
+	// remoteItemCachePruneRatio is the ratio of max size to which the cache is
+	// pruned when it exceeds the max size, so pruning does not happen on every
+	// write.
+	remoteItemCachePruneRatio = 0.9
+)
+
+// remoteItemCache keeps the remote block item files in the local directory, so
+// they can be reused across imports. The directory can be shared by several
+// nodes; the file is written to the temp file and renamed, so the partially
+// written file is never read. When the total size exceeds the max size, the
+// least recently used files are evicted by their modified time.
+type remoteItemCache struct {
+	directory string
+	maxSize   int64
+	size      int64
+	l         sync.Mutex
+}
+
+func newRemoteItemCache(directory string, maxSize int64) (*remoteItemCache, error) {
+	stat, err := remoteItemCacheStats(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &remoteItemCache{
+		directory: directory,
+		maxSize:   maxSize,
+		size:      stat.Size,
+	}, nil
+}
+
+func (c *remoteItemCache) path(uri url.URL, compressFormat string) string {
+	h := valuehash.NewSHA256(util.ConcatBytesSlice(
+		[]byte((&uri).String()),
+		[]byte(compressFormat),
+	))
+
+	return filepath.Join(c.directory, util.DelmSplitStrings(h.String(), "/", 32)) //nolint:gomnd //...
+}
+
+func (c *remoteItemCache) read(
+	uri url.URL,
+	compressFormat string,
+	f func(io.Reader, string) error,
+) (bool, error) {
+	p := c.path(uri, compressFormat)
+
+	switch i, err := os.Open(filepath.Clean(p)); {
+	case os.IsNotExist(err):
+		return false, nil
+	case err != nil:
+		return false, errors.WithStack(err)
+	default:
+		defer func() {
+			_ = i.Close()
+		}()
+
+		touchRemoteItemCacheFile(p)
+
+		return true, f(i, compressFormat)
+	}
+}
+
+// readThrough reads the item from cache; if not cached, it reads from
+// fromRemotes and the read item is written to cache while f reads it.
+func (c *remoteItemCache) readThrough(
+	ctx context.Context,
+	uri url.URL,
+	compressFormat string,
+	f func(io.Reader, string) error,
+	fromRemotes isaac.RemotesBlockItemReadFunc,
+) (known, found bool, _ error) {
+	switch found, err := c.read(uri, compressFormat, f); {
+	case err != nil:
+		return false, false, err
+	case found:
+		return true, true, nil
+	}
+
+	return fromRemotes(ctx, uri, compressFormat, func(r io.Reader, rcompressFormat string) error {
+		w, err := c.newWriter(uri, compressFormat)
+		if err != nil {
+			return err
+		}
+
+		tr := io.TeeReader(r, w)
+
+		if err := f(tr, rcompressFormat); err != nil {
+			w.abort()
+
+			return err
+		}
+
+		// NOTE f may not read until the end.
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			w.abort()
+
+			return errors.WithStack(err)
+		}
+
+		return w.commit()
+	})
+}
+
+// newWriter returns the writer to the temp file; the temp file is renamed to
+// the cache file by commit.
+func (c *remoteItemCache) newWriter(uri url.URL, compressFormat string) (*remoteItemCacheWriter, error) {
+	p := c.path(uri, compressFormat)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(p), remoteItemCacheTempPrefix+"*")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &remoteItemCacheWriter{c: c, f: f, path: p}, nil
+}
+
+func (c *remoteItemCache) added(n int64) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.size += n
+
+	if c.maxSize < 1 || c.size <= c.maxSize {
+		return nil
+	}
+
+	if _, err := pruneRemoteItemCache(
+		c.directory, int64(float64(c.maxSize)*remoteItemCachePruneRatio), 0); err != nil {
+		return err
+	}
+
+	// NOTE the other nodes may write to the shared directory, so the size is
+	// counted again.
+	switch stat, err := remoteItemCacheStats(c.directory); {
+	case err != nil:
+		return err
+	default:
+		c.size = stat.Size
+
+		return nil
+	}
+}
+
+type remoteItemCacheWriter struct {
+	c    *remoteItemCache
+	f    *os.File
+	path string
+	n    int64
+}
+
+func (w *remoteItemCacheWriter) Write(b []byte) (int, error) {
+	n, err := w.f.Write(b)
+	w.n += int64(n)
+
+	return n, errors.WithStack(err)
+}
+
+func (w *remoteItemCacheWriter) commit() error {
+	err := w.f.Close()
+	if err == nil {
+		err = os.Rename(w.f.Name(), w.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(w.f.Name())
+
+		return errors.WithStack(err)
+	}
+
+	return w.c.added(w.n)
+}
+
+func (w *remoteItemCacheWriter) abort() {
+	_ = w.f.Close()
+	_ = os.Remove(w.f.Name())
+}
+
+// touchRemoteItemCacheFile updates the modified time of file; the modified time
+// is used as the last access time, because atime is not reliable.
+func touchRemoteItemCacheFile(p string) {
+	now := localtime.Now()
+
+	_ = os.Chtimes(p, now, now)
+}
+
+type remoteItemCacheStat struct {
+	Oldest    time.Time `json:"oldest,omitempty"`
+	Newest    time.Time `json:"newest,omitempty"`
+	Directory string    `json:"directory"`
+	Files     int64     `json:"files"`
+	Size      int64     `json:"size"`
+	TempFiles int64     `json:"temp_files"`
+}
+
+type remoteItemCacheFile struct {
+	modTime time.Time
+	path    string
+	size    int64
+}
+
+func remoteItemCacheStats(directory string) (remoteItemCacheStat, error) {
+	stat := remoteItemCacheStat{Directory: directory}
+
+	err := walkRemoteItemCache(directory,
+		func(i remoteItemCacheFile) {
+			stat.Files++
+			stat.Size += i.size
+
+			if stat.Oldest.IsZero() || i.modTime.Before(stat.Oldest) {
+				stat.Oldest = i.modTime
+			}
+
+			if i.modTime.After(stat.Newest) {
+				stat.Newest = i.modTime
+			}
+		},
+		func(remoteItemCacheFile) {
+			stat.TempFiles++
+		},
+	)
+
+	return stat, err
+}
+
+type remoteItemCachePruned struct {
+	Files int64 `json:"files"`
+	Size  int64 `json:"size"`
+}
+
+// pruneRemoteItemCache removes the files older than olderThan and then removes
+// the least recently used files until the total size is not over maxSize. The
+// zero maxSize or olderThan is ignored. The stale temp files are also removed.
+func pruneRemoteItemCache(directory string, maxSize int64, olderThan time.Duration) (remoteItemCachePruned, error) {
+	var pruned remoteItemCachePruned
+
+	var files []remoteItemCacheFile
+	var size int64
+
+	now := localtime.Now()
+
+	if err := walkRemoteItemCache(directory,
+		func(i remoteItemCacheFile) {
+			files = append(files, i)
+			size += i.size
+		},
+		func(i remoteItemCacheFile) {
+			if now.Sub(i.modTime) > remoteItemCacheStaleTemp {
+				_ = os.Remove(i.path)
+			}
+		},
+	); err != nil {
+		return pruned, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for i := range files {
+		switch {
+		case olderThan > 0 && now.Sub(files[i].modTime) > olderThan:
+		case maxSize > 0 && size > maxSize:
+		default:
+			continue
+		}
+
+		switch err := os.Remove(files[i].path); {
+		case os.IsNotExist(err):
+			// NOTE removed by the other node.
+		case err != nil:
+			return pruned, errors.WithStack(err)
+		default:
+			pruned.Files++
+			pruned.Size += files[i].size
+		}
+
+		size -= files[i].size
+	}
+
+	return pruned, nil
+}
+
+func walkRemoteItemCache(
+	directory string,
+	f func(remoteItemCacheFile),
+	tempf func(remoteItemCacheFile),
+) error {
+	return errors.WithStack(filepath.WalkDir(directory, func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case os.IsNotExist(err):
+			return nil
+		case err != nil:
+			return err
+		case d.IsDir():
+			return nil
+		}
+
+		fi, err := d.Info()
+
+		switch {
+		case os.IsNotExist(err):
+			return nil
+		case err != nil:
+			return err
+		case !fi.Mode().IsRegular():
+			return nil
+		}
+
+		i := remoteItemCacheFile{path: p, size: fi.Size(), modTime: fi.ModTime()}
+
+		if strings.HasPrefix(d.Name(), remoteItemCacheTempPrefix) {
+			tempf(i)
+
+			return nil
+		}
+
+		f(i)
+
+		return nil
+	}))
+}
+
+// ByteSizeFlag parses the size with the binary unit, like `512MiB` or `10G`.
+type ByteSizeFlag struct {
+	n int64
+}
+
+func (f *ByteSizeFlag) UnmarshalText(b []byte) error {
+	s := strings.TrimSpace(strings.ToUpper(string(b)))
+
+	if len(s) < 1 {
+		f.n = 0
+
+		return nil
+	}
+
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	var unit int64 = 1
+
+	if i := strings.IndexAny(s, "KMGTP"); i >= 0 && i == len(s)-1 {
+		for j := 0; j <= strings.Index("KMGTP", s[i:]); j++ {
+			unit <<= 10 //nolint:gomnd //...
+		}
+
+		s = s[:i]
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+
+	switch {
+	case err != nil:
+		return errors.Errorf("invalid size, %q", string(b))
+	case n < 0:
+		return errors.Errorf("negative size, %q", string(b))
+	}
+
+	f.n = n * unit
+
+	return nil
+}
+
+func (f ByteSizeFlag) Size() int64 {
+	return f.n
+}
+
+func (f ByteSizeFlag) MarshalText() ([]byte, error) {
+	return []byte(humanBytes(uint64(f.n))), nil
+}
@@ -0,0 +1,118 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRemoteItemCacheReadThrough(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 1000)
+
+	cases := []struct {
+		name     string
+		f        func(io.Reader, string) error
+		err      bool
+		cached   bool
+		maxSize  int64
+		readSize int
+	}{
+		{
+			name:     "read all",
+			f:        func(r io.Reader, _ string) error { _, err := io.ReadAll(r); return err },
+			cached:   true,
+			readSize: len(body),
+		},
+		{
+			name:     "read partial",
+			f:        func(r io.Reader, _ string) error { _, err := r.Read(make([]byte, 10)); return err },
+			cached:   true,
+			readSize: len(body),
+		},
+		{
+			name: "failed",
+			f:    func(io.Reader, string) error { return errors.Errorf("failed") },
+			err:  true,
+		},
+		{
+			name:     "pruned",
+			f:        func(r io.Reader, _ string) error { _, err := io.ReadAll(r); return err },
+			maxSize:  int64(len(body)) - 1,
+			readSize: len(body),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cache, err := newRemoteItemCache(t.TempDir(), c.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			uri, _ := url.Parse("https://a.b/c")
+
+			var remoteCalled int
+
+			fromRemotes := func(
+				_ context.Context, _ url.URL, compressFormat string, f func(io.Reader, string) error,
+			) (bool, bool, error) {
+				remoteCalled++
+
+				return true, true, f(bytes.NewReader(body), compressFormat)
+			}
+
+			_, _, err = cache.readThrough(context.Background(), *uri, "gz", c.f, fromRemotes)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			stat, err := remoteItemCacheStats(cache.directory)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if stat.TempFiles > 0 {
+				t.Errorf("temp files left, %d", stat.TempFiles)
+			}
+
+			var cached []byte
+
+			found, err := cache.read(*uri, "gz", func(r io.Reader, _ string) error {
+				b, err := io.ReadAll(r)
+				cached = b
+
+				return err
+			})
+
+			switch {
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			case found != c.cached:
+				t.Fatalf("cached: expected %v, got %v", c.cached, found)
+			case !found:
+				return
+			case !bytes.Equal(cached, body[:c.readSize]):
+				t.Errorf("cached body does not match; %d != %d", len(cached), c.readSize)
+			}
+
+			// NOTE cached item is read without remote.
+			if _, _, err := cache.readThrough(context.Background(), *uri, "gz", c.f, fromRemotes); err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if remoteCalled != 1 {
+				t.Errorf("remote called %d times", remoteCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	csteps "github.com/imfact-labs/currency-model/app/runtime/steps"
+	"github.com/imfact-labs/imfact-model/runtime/contracts"
+	"github.com/imfact-labs/imfact-model/runtime/steps"
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/hint"
+	"github.com/imfact-labs/mitum2/util/logging"
+	"github.com/imfact-labs/mitum2/util/ps"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var PNameReplayBlocks = ps.Name("replay-blocks")
+
+// ReplayBlocksCommand re-executes the operations of the stored blocks with the
+// operation processors of this binary and compares the result with the stored
+// blocks.
+type ReplayBlocksCommand struct { //nolint:govet //...
+	launch.DesignFlag
+	launch.PrivatekeyFlags
+	ProgressFlags
+	HeightRange     launch.RangeFlag `name:"range" help:"<from>-<to>" default:""`
+	Modules         []string         `name:"modules" sep:"," help:"model modules to compose; overrides 'modules' of design" placeholder:"module"`
+	Report          string           `name:"report" help:"write replay report to file" placeholder:"file"`
+	log             *zerolog.Logger
+	launch.DevFlags `embed:"" prefix:"dev."`
+	fromHeight      base.Height
+	toHeight        base.Height
+	progress        *blockProgress
+	states          *replayStates
+}
+
+type replayBlocksReport struct {
+	Divergence *replayDivergence `json:"divergence,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	From       base.Height       `json:"from"`
+	To         base.Height       `json:"to"`
+	Replayed   uint64            `json:"replayed"`
+}
+
+// replayDivergence is the first difference between the replayed block and the
+// stored block.
+type replayDivergence struct {
+	Item     base.BlockItemType `json:"item"`
+	Key      string             `json:"key,omitempty"`
+	Stored   string             `json:"stored"`
+	Replayed string             `json:"replayed"`
+	Height   base.Height        `json:"height"`
+	Index    uint64             `json:"index"`
+}
+
+func (cmd *ReplayBlocksCommand) Run(pctx context.Context) error {
+	var log *logging.Logging
+	if err := util.LoadFromContextOK(pctx, launch.LoggingContextKey, &log); err != nil {
+		return err
+	}
+
+	if err := cmd.ProgressFlags.IsValid(); err != nil {
+		return err
+	}
+
+	cmd.fromHeight, cmd.toHeight = base.NilHeight, base.NilHeight
+
+	if h := cmd.HeightRange.From(); h != nil {
+		cmd.fromHeight = base.Height(*h)
+
+		if err := cmd.fromHeight.IsValid(nil); err != nil {
+			return errors.WithMessagef(err, "invalid from height; from=%d", *h)
+		}
+	}
+
+	if h := cmd.HeightRange.To(); h != nil {
+		cmd.toHeight = base.Height(*h)
+
+		if err := cmd.toHeight.IsValid(nil); err != nil {
+			return errors.WithMessagef(err, "invalid to height; to=%d", *h)
+		}
+
+		if cmd.fromHeight > cmd.toHeight {
+			return errors.Errorf("from height is higher than to; from=%d to=%d", cmd.fromHeight, cmd.toHeight)
+		}
+	}
+
+	log.Log().Debug().
+		Interface("design", cmd.DesignFlag).
+		Interface("privatekey", cmd.PrivatekeyFlags).
+		Interface("dev", cmd.DevFlags).
+		Interface("from_height", cmd.fromHeight).
+		Interface("to_height", cmd.toHeight).
+		Interface("progress", cmd.ProgressFlags).
+		Strs("modules", cmd.Modules).
+		Str("report", cmd.Report).
+		Msg("flags")
+
+	cmd.log = log.Log()
+
+	switch ids, err := loadModules(cmd.DesignFlag, cmd.Modules); {
+	case err != nil:
+		return err
+	default:
+		cmd.log.Debug().Strs("modules", ids).Msg("modules composed")
+	}
+
+	nctx := util.ContextWithValues(pctx, map[util.ContextKey]interface{}{
+		launch.DesignFlagContextKey: cmd.DesignFlag,
+		launch.DevFlagsContextKey:   cmd.DevFlags,
+		launch.PrivatekeyContextKey: string(cmd.PrivatekeyFlags.Flag.Body()),
+	})
+
+	pps := ps.NewPS("cmd-replay-blocks")
+	_ = pps.SetLogging(log)
+
+	_ = pps.
+		AddOK(launch.PNameEncoder, csteps.PEncoder, nil).
+		AddOK(launch.PNameDesign, launch.PLoadDesign, nil, launch.PNameEncoder).
+		AddOK(launch.PNameLocal, launch.PLocal, nil, launch.PNameDesign).
+		AddOK(launch.PNameBlockItemReaders, launch.PBlockItemReaders, nil, launch.PNameDesign).
+		AddOK(launch.PNameStorage, launch.PStorage, launch.PCloseStorage, launch.PNameLocal)
+
+	_ = pps.POK(launch.PNameEncoder).
+		PostAddOK(launch.PNameAddHinters, steps.PAddHinters)
+
+	_ = pps.POK(launch.PNameDesign).
+		PostAddOK(launch.PNameCheckDesign, launch.PCheckDesign)
+
+	_ = pps.POK(launch.PNameBlockItemReaders).
+		PreAddOK(launch.PNameBlockItemReadersDecompressFunc, launch.PBlockItemReadersDecompressFunc).
+		PostAddOK(launch.PNameRemotesBlockItemReaderFunc, launch.PRemotesBlockItemReaderFunc)
+
+	pstorage := pps.POK(launch.PNameStorage).
+		PreAddOK(launch.PNameCheckLocalFS, launch.PCheckLocalFS).
+		PreAddOK(launch.PNameLoadDatabase, launch.PLoadDatabase).
+		PostAddOK(launch.PNameCheckLeveldbStorage, launch.PCheckLeveldbStorage).
+		PostAddOK(launch.PNameLoadFromDatabase, launch.PLoadFromDatabase).
+		PostAddOK(launch.PNameCheckBlocksOfStorage, launch.PCheckBlocksOfStorage).
+		PostAddOK(launch.PNamePatchBlockItemReaders, launch.PPatchBlockItemReaders).
+		PostAddOK(launch.PNameNodeInfo, launch.PNodeInfo).
+		PostAddOK(launch.PNameSuffrageCandidateLimiterSet, csteps.PSuffrageCandidateLimiterSet).
+		PostAddOK(csteps.PNameOperationProcessorsMap, csteps.POperationProcessorsMap)
+
+	entries := mustBuildModuleRegistry().Entries()
+	for i := range entries {
+		entry := entries[i]
+		for j := range entry.OperationProcessors {
+			if entry.OperationProcessors[j].Name == launch.PNameOperationProcessorsMap {
+				// currency default processor map is already set.
+				continue
+			}
+
+			_ = pstorage.PostAddOK(entry.OperationProcessors[j].Name, entry.OperationProcessors[j].Func)
+		}
+	}
+
+	_ = pstorage.PostAddOK(PNameReplayBlocks, cmd.pReplayBlocks)
+
+	cmd.log.Debug().Interface("process", pps.Verbose()).Msg("process ready")
+
+	nctx, err := pps.Run(nctx)
+	defer func() {
+		cmd.log.Debug().Interface("process", pps.Verbose()).Msg("process will be closed")
+
+		if _, err = pps.Close(nctx); err != nil {
+			cmd.log.Error().Err(err).Msg("failed to close")
+		}
+	}()
+
+	return err
+}
+
+func (cmd *ReplayBlocksCommand) pReplayBlocks(pctx context.Context) (context.Context, error) {
+	e := util.StringError("replay blocks")
+
+	var design launch.NodeDesign
+	var db isaac.Database
+	var oprs *hint.CompatibleSet[isaac.NewOperationProcessorInternalFunc]
+	var oprsB *hint.CompatibleSet[contracts.NewOperationProcessorInternalWithProposalFunc]
+	var newReaders func(context.Context, string, *isaac.BlockItemReadersArgs) (*isaac.BlockItemReaders, error)
+
+	if err := util.LoadFromContextOK(pctx,
+		launch.DesignContextKey, &design,
+		launch.CenterDatabaseContextKey, &db,
+		launch.OperationProcessorsMapContextKey, &oprs,
+		contracts.OperationProcessorsMapBContextKey, &oprsB,
+		launch.NewBlockItemReadersFuncContextKey, &newReaders,
+	); err != nil {
+		return pctx, e.Wrap(err)
+	}
+
+	var readers *isaac.BlockItemReaders
+
+	switch i, err := newReaders(pctx, launch.LocalFSDataDirectory(design.Storage.Base), nil); {
+	case err != nil:
+		return pctx, err
+	default:
+		readers = i
+	}
+
+	var last base.Height
+
+	switch fromHeight, toHeight, i, err := checkLastHeight(pctx, readers.Root(), cmd.fromHeight, cmd.toHeight); {
+	case err != nil:
+		return pctx, e.Wrap(err)
+	default:
+		cmd.fromHeight = fromHeight
+		cmd.toHeight = toHeight
+		last = i
+
+		cmd.log.Debug().
+			Interface("from_height", cmd.fromHeight).
+			Interface("to_height", cmd.toHeight).
+			Interface("last", last).
+			Msg("heights checked")
+	}
+
+	cmd.progress = newBlockProgress(cmd.ProgressFlags, "replay-blocks", cmd.fromHeight, last)
+	cmd.progress.start(pctx)
+
+	defer cmd.progress.stop()
+
+	itemf := cmd.progress.itemFunc(readers.Item)
+
+	// NOTE the states before from height are not counted in progress.
+	cmd.states = newReplayStates(db, readers.Item, cmd.fromHeight)
+
+	report := replayBlocksReport{From: cmd.fromHeight, To: last}
+
+	newOperationProcessor := func(
+		height base.Height, pr base.ProposalSignFact, ht hint.Hint, getStatef base.GetStateFunc,
+	) (base.OperationProcessor, error) {
+		if v, found := oprs.Find(ht); found {
+			return v(height, getStatef)
+		}
+
+		if w, found := oprsB.Find(ht); found {
+			return w(height, pr, getStatef)
+		}
+
+		return nil, nil
+	}
+
+	var err error
+
+	for height := cmd.fromHeight; height <= last; height++ {
+		var d *replayDivergence
+
+		if d, err = replayBlock(pctx, itemf, height, cmd.states, newOperationProcessor); err != nil {
+			err = errors.WithMessagef(err, "height %d", height)
+
+			break
+		}
+
+		cmd.progress.blockDone(height)
+
+		if d != nil {
+			report.Divergence = d
+
+			break
+		}
+
+		report.Replayed++
+
+		cmd.log.Debug().Interface("height", height).Msg("block replayed")
+	}
+
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	if perr := report.print(cmd.Report); perr != nil {
+		cmd.log.Error().Err(perr).Str("report", cmd.Report).Msg("failed to write report")
+	}
+
+	switch {
+	case err != nil:
+		return pctx, e.Wrap(err)
+	case report.Divergence != nil:
+		return pctx, e.Errorf("diverged at height %d, %s", report.Divergence.Height, report.Divergence.Item)
+	default:
+		return pctx, nil
+	}
+}
+
+// print prints report to stdout; with file, report is also written to file.
+func (r replayBlocksReport) print(f string) error {
+	b, err := util.MarshalJSONIndent(r)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(os.Stdout, string(b))
+
+	if len(f) < 1 {
+		return nil
+	}
+
+	return errors.WithStack(os.WriteFile(filepath.Clean(f), b, 0o600))
+}
@@ -0,0 +1,493 @@
+package cmds
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	isaacblock "github.com/imfact-labs/mitum2/isaac/block"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/fixedtree"
+	"github.com/imfact-labs/mitum2/util/hint"
+	"github.com/pkg/errors"
+)
+
+type replayNewOperationProcessorFunc func(
+	base.Height, base.ProposalSignFact, hint.Hint, base.GetStateFunc,
+) (base.OperationProcessor, error)
+
+// replayStates provides the states of the previous height of the replaying
+// block. The database has only the last states, so the states before from
+// height of the keys changed after from height are found from the stored
+// blocks.
+type replayStates struct {
+	dbStatef func(string) (base.State, bool, error)
+	lastf    func() (base.Height, error)
+	statesf  func(base.Height) ([]base.State, error)
+	applied  map[string]base.State
+	// NOTE prior has the states before from height of the keys changed after
+	// from height; nil state means the key is created after from height.
+	prior map[string]base.State
+	from  base.Height
+	sync.Mutex
+}
+
+func newReplayStates(db isaac.Database, itemf isaac.BlockItemReadersItemFunc, from base.Height) *replayStates {
+	return &replayStates{
+		dbStatef: db.State,
+		lastf: func() (base.Height, error) {
+			switch bm, found, err := db.LastBlockMap(); {
+			case err != nil:
+				return base.NilHeight, err
+			case !found:
+				return base.NilHeight, util.ErrNotFound.Errorf("last blockmap")
+			default:
+				return bm.Manifest().Height(), nil
+			}
+		},
+		statesf: func(height base.Height) ([]base.State, error) {
+			_, sts, _, err := isaac.BlockItemReadersDecodeItems[base.State](
+				itemf, height, base.BlockItemStates, nil, nil)
+
+			return sts, errors.WithMessagef(err, "states of height %d", height)
+		},
+		from:    from,
+		applied: map[string]base.State{},
+	}
+}
+
+// state returns the state before the current block; current is the stored
+// states of the current block.
+func (s *replayStates) state(key string, current map[string]base.State) (base.State, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if st, found := s.applied[key]; found {
+		return st, true, nil
+	}
+
+	if st, found := current[key]; found && st.Previous() == nil {
+		// NOTE created in current block
+		return nil, false, nil
+	}
+
+	switch st, found, err := s.dbStatef(key); {
+	case err != nil:
+		return nil, false, err
+	case !found:
+		return nil, false, nil
+	case st.Height() < s.from:
+		return st, true, nil
+	}
+
+	// NOTE the last state is changed after from height.
+	if s.prior == nil {
+		prior, err := s.priorStates()
+		if err != nil {
+			return nil, false, err
+		}
+
+		s.prior = prior
+	}
+
+	switch st, found := s.prior[key]; {
+	case !found:
+		return nil, false, errors.Errorf("state of %q before height %d not found in stored blocks", key, s.from)
+	case st == nil:
+		return nil, false, nil
+	default:
+		return st, true, nil
+	}
+}
+
+// apply sets the stored states of replayed block, so the next block starts
+// from the stored states, not from the replayed ones.
+func (s *replayStates) apply(sts []base.State) {
+	s.Lock()
+	defer s.Unlock()
+
+	for i := range sts {
+		s.applied[sts[i].Key()] = sts[i]
+	}
+}
+
+// priorStates collects the keys changed after from height and finds their
+// states before from height. Each stored block is read at most once and the
+// walk stops when all the keys are resolved.
+func (s *replayStates) priorStates() (map[string]base.State, error) {
+	last, err := s.lastf()
+	if err != nil {
+		return nil, err
+	}
+
+	prior := map[string]base.State{}
+	keys := map[string]struct{}{}
+
+	for height := s.from; height <= last; height++ {
+		sts, err := s.statesf(height)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range sts {
+			k := sts[i].Key()
+
+			if _, found := prior[k]; found {
+				continue
+			}
+
+			if _, found := keys[k]; found {
+				continue
+			}
+
+			switch {
+			case sts[i].Previous() == nil:
+				prior[k] = nil
+			default:
+				keys[k] = struct{}{}
+			}
+		}
+	}
+
+	for height := s.from - 1; height >= base.GenesisHeight && len(keys) > 0; height-- {
+		sts, err := s.statesf(height)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range sts {
+			if _, found := keys[sts[i].Key()]; found {
+				prior[sts[i].Key()] = sts[i]
+
+				delete(keys, sts[i].Key())
+			}
+		}
+	}
+
+	return prior, nil
+}
+
+// replayBlock processes the operations of the stored block like the proposal
+// processor does and returns the first divergence.
+func replayBlock(
+	ctx context.Context,
+	itemf isaac.BlockItemReadersItemFunc,
+	height base.Height,
+	states *replayStates,
+	newOperationProcessor replayNewOperationProcessorFunc,
+) (*replayDivergence, error) {
+	var bm base.BlockMap
+
+	switch i, found, err := isaac.BlockItemReadersDecode[base.BlockMap](itemf, height, base.BlockItemMap, nil); {
+	case err != nil:
+		return nil, err
+	case !found:
+		return nil, util.ErrNotFound.Errorf("blockmap")
+	default:
+		bm = i
+	}
+
+	pr, ops, sts, opstree, _, _, err := isaacblock.LoadBlockItemsFromReader(bm, itemf, height)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]base.State{}
+
+	for i := range sts {
+		current[sts[i].Key()] = sts[i]
+	}
+
+	getStatef := func(key string) (base.State, bool, error) {
+		return states.state(key, current)
+	}
+
+	r := newBlockReplayer(height, pr, getStatef, newOperationProcessor)
+	defer r.close()
+
+	nodes, err := r.processOperations(ctx, opstree, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	if d := compareReplayOperationsTree(height, opstree, nodes); d != nil {
+		return d, nil
+	}
+
+	replayed, err := r.closeStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if d := compareReplayStates(height, sts, replayed); d != nil {
+		return d, nil
+	}
+
+	states.apply(sts)
+
+	return nil, nil
+}
+
+type blockReplayer struct {
+	pr                    base.ProposalSignFact
+	getStatef             base.GetStateFunc
+	newOperationProcessor replayNewOperationProcessorFunc
+	merger                *isaacblock.DefaultStatesMerger
+	oprs                  map[string]base.OperationProcessor
+	height                base.Height
+}
+
+func newBlockReplayer(
+	height base.Height,
+	pr base.ProposalSignFact,
+	getStatef base.GetStateFunc,
+	newOperationProcessor replayNewOperationProcessorFunc,
+) *blockReplayer {
+	return &blockReplayer{
+		height:                height,
+		pr:                    pr,
+		getStatef:             getStatef,
+		newOperationProcessor: newOperationProcessor,
+		merger:                isaacblock.NewDefaultStatesMerger(height, getStatef, int64(runtime.NumCPU())),
+		oprs:                  map[string]base.OperationProcessor{},
+	}
+}
+
+// processOperations processes the operations by the order of stored operations
+// tree. The operations, which are not in the stored operations, like the
+// missing operations in proposal, are not processed and the stored node is
+// kept.
+func (r *blockReplayer) processOperations(
+	ctx context.Context, opstree fixedtree.Tree, ops []base.Operation,
+) ([]fixedtree.Node, error) {
+	byfact := map[string]base.Operation{}
+
+	for i := range ops {
+		byfact[ops[i].Fact().Hash().String()] = ops[i]
+	}
+
+	nodes := make([]fixedtree.Node, opstree.Len())
+
+	pctx := ctx
+
+	for i, node := range opstree.Nodes() {
+		facthash, _ := base.ParseTreeNodeOperationKey(node.Key())
+
+		op, found := byfact[facthash.String()]
+		if !found {
+			nodes[i] = node
+
+			continue
+		}
+
+		switch nctx, n, err := r.processOperation(pctx, uint64(i), op); {
+		case err != nil:
+			return nil, errors.WithMessagef(err, "operation %q", op.Fact().Hash())
+		default:
+			pctx = nctx
+			nodes[i] = n
+		}
+	}
+
+	return nodes, nil
+}
+
+// processOperation returns nil node if the operation is suspended.
+func (r *blockReplayer) processOperation(
+	ctx context.Context, index uint64, op base.Operation,
+) (context.Context, fixedtree.Node, error) {
+	opp, err := r.operationProcessor(op.Hint())
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	var pctx context.Context
+	var reason base.OperationProcessReasonError
+
+	switch {
+	case opp != nil:
+		pctx, reason, err = opp.PreProcess(ctx, op, r.getStatef)
+	default:
+		pctx, reason, err = op.PreProcess(ctx, r.getStatef)
+	}
+
+	switch {
+	case errors.Is(err, isaac.ErrSuspendOperation):
+		return pctx, nil, nil
+	case err != nil:
+		return pctx, nil, errors.WithMessage(err, "pre process")
+	case reason != nil:
+		return pctx, base.NewNotInStateOperationFixedtreeNode(op.Fact().Hash(), reason.Msg()), nil
+	}
+
+	var stvs []base.StateMergeValue
+
+	switch {
+	case opp != nil:
+		stvs, reason, err = opp.Process(pctx, op, r.getStatef)
+	default:
+		stvs, reason, err = op.Process(pctx, r.getStatef)
+	}
+
+	if err != nil {
+		return pctx, nil, errors.WithMessage(err, "process")
+	}
+
+	if err := r.merger.SetStates(pctx, index, stvs, op.Fact().Hash()); err != nil {
+		return pctx, nil, err
+	}
+
+	var msg string
+	if reason != nil {
+		msg = reason.Msg()
+	}
+
+	if len(stvs) > 0 {
+		return pctx, base.NewInStateOperationFixedtreeNode(op.Fact().Hash(), msg), nil
+	}
+
+	return pctx, base.NewNotInStateOperationFixedtreeNode(op.Fact().Hash(), msg), nil
+}
+
+func (r *blockReplayer) operationProcessor(ht hint.Hint) (base.OperationProcessor, error) {
+	if opp, found := r.oprs[ht.String()]; found {
+		return opp, nil
+	}
+
+	opp, err := r.newOperationProcessor(r.height, r.pr, ht, r.getStatef)
+	if err != nil {
+		return nil, err
+	}
+
+	r.oprs[ht.String()] = opp
+
+	return opp, nil
+}
+
+func (r *blockReplayer) closeStates(ctx context.Context) ([]base.State, error) {
+	var l sync.Mutex
+	var sts []base.State
+
+	if err := r.merger.CloseStates(
+		ctx,
+		func(uint64) error { return nil },
+		func(st base.State, _, _ uint64) error {
+			l.Lock()
+			defer l.Unlock()
+
+			sts = append(sts, st)
+
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return sts, nil
+}
+
+func (r *blockReplayer) close() {
+	for k := range r.oprs {
+		if r.oprs[k] != nil {
+			_ = r.oprs[k].Close()
+		}
+	}
+
+	_ = r.merger.Close()
+}
+
+func compareReplayOperationsTree(height base.Height, opstree fixedtree.Tree, nodes []fixedtree.Node) *replayDivergence {
+	for i, stored := range opstree.Nodes() {
+		d := &replayDivergence{
+			Height: height,
+			Item:   base.BlockItemOperationsTree,
+			Index:  uint64(i),
+			Stored: replayOperationNodeString(stored),
+		}
+
+		switch n := nodes[i]; {
+		case n == nil:
+			d.Replayed = "suspended"
+
+			return d
+		case n.Key() != stored.Key(),
+			replayOperationNodeReason(n) != replayOperationNodeReason(stored):
+			d.Replayed = replayOperationNodeString(n)
+
+			return d
+		}
+	}
+
+	return nil
+}
+
+func compareReplayStates(height base.Height, stored, replayed []base.State) *replayDivergence {
+	sortStates := func(sts []base.State) {
+		sort.Slice(sts, func(i, j int) bool {
+			return sts[i].Key() < sts[j].Key()
+		})
+	}
+
+	sortStates(stored)
+	sortStates(replayed)
+
+	stateString := func(sts []base.State, i int) string {
+		if i >= len(sts) {
+			return "missing"
+		}
+
+		return sts[i].Hash().String()
+	}
+
+	for i := 0; i < len(stored) || i < len(replayed); i++ {
+		d := &replayDivergence{
+			Height:   height,
+			Item:     base.BlockItemStates,
+			Index:    uint64(i),
+			Stored:   stateString(stored, i),
+			Replayed: stateString(replayed, i),
+		}
+
+		switch {
+		case i >= len(stored):
+			d.Key = replayed[i].Key()
+		case i >= len(replayed):
+			d.Key = stored[i].Key()
+		case stored[i].Key() != replayed[i].Key():
+			d.Key = stored[i].Key()
+
+			if replayed[i].Key() < d.Key {
+				d.Key = replayed[i].Key()
+			}
+		case !stored[i].Hash().Equal(replayed[i].Hash()):
+			d.Key = stored[i].Key()
+		default:
+			continue
+		}
+
+		return d
+	}
+
+	return nil
+}
+
+func replayOperationNodeReason(n fixedtree.Node) string {
+	if i, ok := n.(base.OperationFixedtreeNode); ok && i.Reason() != nil {
+		return i.Reason().Msg()
+	}
+
+	return ""
+}
+
+func replayOperationNodeString(n fixedtree.Node) string {
+	s := n.Key()
+
+	if reason := replayOperationNodeReason(n); len(reason) > 0 {
+		s += "; " + reason
+	}
+
+	return s
+}
@@ -0,0 +1,205 @@
+package cmds
+
+import (
+	"testing"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/fixedtree"
+	"github.com/imfact-labs/mitum2/util/valuehash"
+)
+
+func replayTestState(height base.Height, key, op string, previous util.Hash) base.State {
+	return base.NewBaseState(height, key, nil, previous, []util.Hash{valuehash.NewSHA256([]byte(op))})
+}
+
+func TestCompareReplayStates(t *testing.T) {
+	a := replayTestState(3, "a", "0", nil)
+	b := replayTestState(3, "b", "0", nil)
+	c := replayTestState(3, "c", "0", nil)
+
+	cases := []struct {
+		name     string
+		stored   []base.State
+		replayed []base.State
+		key      string
+	}{
+		{name: "same", stored: []base.State{a, b}, replayed: []base.State{b, a}},
+		{name: "empty", stored: nil, replayed: nil},
+		{name: "different hash", stored: []base.State{a, b}, replayed: []base.State{a, replayTestState(3, "b", "1", nil)}, key: "b"},
+		{name: "missing in replayed", stored: []base.State{a, b}, replayed: []base.State{a}, key: "b"},
+		{name: "missing in stored", stored: []base.State{a}, replayed: []base.State{a, c}, key: "c"},
+		{name: "different key", stored: []base.State{a, c}, replayed: []base.State{a, b}, key: "b"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := compareReplayStates(3, c.stored, c.replayed)
+
+			switch {
+			case len(c.key) < 1:
+				if d != nil {
+					t.Fatalf("expected no divergence, got %+v", d)
+				}
+			case d == nil:
+				t.Fatal("expected divergence")
+			case d.Item != base.BlockItemStates:
+				t.Errorf("item: expected %q, got %q", base.BlockItemStates, d.Item)
+			case d.Key != c.key:
+				t.Errorf("key: expected %q, got %q", c.key, d.Key)
+			}
+		})
+	}
+}
+
+func TestCompareReplayOperationsTree(t *testing.T) {
+	fa := valuehash.NewSHA256([]byte("a"))
+	fb := valuehash.NewSHA256([]byte("b"))
+
+	stored := []fixedtree.Node{
+		base.NewInStateOperationFixedtreeNode(fa, ""),
+		base.NewNotInStateOperationFixedtreeNode(fb, "failed"),
+	}
+
+	opstree, err := fixedtree.NewTree(base.OperationFixedtreeHint, stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		nodes    []fixedtree.Node
+		index    uint64
+		replayed string
+		diverged bool
+	}{
+		{name: "same", nodes: stored},
+		{
+			name:     "suspended",
+			nodes:    []fixedtree.Node{stored[0], nil},
+			diverged: true,
+			index:    1,
+			replayed: "suspended",
+		},
+		{
+			name:     "not in state",
+			nodes:    []fixedtree.Node{base.NewNotInStateOperationFixedtreeNode(fa, ""), stored[1]},
+			diverged: true,
+			replayed: fa.String() + "-",
+		},
+		{
+			name:     "different reason",
+			nodes:    []fixedtree.Node{stored[0], base.NewNotInStateOperationFixedtreeNode(fb, "other")},
+			diverged: true,
+			index:    1,
+			replayed: fb.String() + "-; other",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := compareReplayOperationsTree(3, opstree, c.nodes)
+
+			switch {
+			case !c.diverged:
+				if d != nil {
+					t.Fatalf("expected no divergence, got %+v", d)
+				}
+			case d == nil:
+				t.Fatal("expected divergence")
+			case d.Index != c.index:
+				t.Errorf("index: expected %d, got %d", c.index, d.Index)
+			case d.Replayed != c.replayed:
+				t.Errorf("replayed: expected %q, got %q", c.replayed, d.Replayed)
+			}
+		})
+	}
+}
+
+func TestReplayStatesState(t *testing.T) {
+	// NOTE a is changed at 1 and 5, b is created at 4, c is changed at 0 and
+	// 6, d is not changed after 2 and e is changed at 5 without history.
+	a1 := replayTestState(1, "a", "a1", nil)
+	a5 := replayTestState(5, "a", "a5", a1.Hash())
+	b4 := replayTestState(4, "b", "b4", nil)
+	c0 := replayTestState(0, "c", "c0", nil)
+	c6 := replayTestState(6, "c", "c6", c0.Hash())
+	d2 := replayTestState(2, "d", "d2", nil)
+	e5 := replayTestState(5, "e", "e5", valuehash.NewSHA256([]byte("unknown")))
+
+	blocks := map[base.Height][]base.State{
+		0: {c0},
+		1: {a1},
+		2: {d2},
+		4: {b4},
+		5: {a5, e5},
+		6: {c6},
+	}
+
+	last := map[string]base.State{"a": a5, "b": b4, "c": c6, "d": d2, "e": e5}
+
+	var read []base.Height
+
+	s := &replayStates{
+		dbStatef: func(key string) (base.State, bool, error) {
+			st, found := last[key]
+
+			return st, found, nil
+		},
+		lastf: func() (base.Height, error) { return 6, nil },
+		statesf: func(height base.Height) ([]base.State, error) {
+			read = append(read, height)
+
+			return blocks[height], nil
+		},
+		from:    4,
+		applied: map[string]base.State{},
+	}
+
+	cases := []struct {
+		key      string
+		expected base.State
+		err      bool
+	}{
+		{key: "a", expected: a1},
+		{key: "b"},
+		{key: "c", expected: c0},
+		{key: "d", expected: d2},
+		{key: "e", err: true},
+		{key: "unknown"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.key, func(t *testing.T) {
+			st, found, err := s.state(c.key, nil)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			case c.expected == nil:
+				if found {
+					t.Errorf("expected not found, got %v", st.Hash())
+				}
+			case !found:
+				t.Error("expected found")
+			case !st.Hash().Equal(c.expected.Hash()):
+				t.Errorf("expected %v, got %v", c.expected.Hash(), st.Hash())
+			}
+		})
+	}
+
+	// NOTE each block is read once.
+	seen := map[base.Height]struct{}{}
+
+	for _, h := range read {
+		if _, found := seen[h]; found {
+			t.Errorf("height %d read again", h)
+		}
+
+		seen[h] = struct{}{}
+	}
+}
@@ -18,8 +18,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-type RunCommand struct {
+type RunCommand struct { //nolint:govet //...
 	ccmds.RunCommand
+	Modules []string `name:"modules" sep:"," help:"model modules to compose; overrides 'modules' of design" placeholder:"module"`
 }
 
 func (cmd *RunCommand) Run(pctx context.Context) error {
@@ -36,10 +37,18 @@ func (cmd *RunCommand) Run(pctx context.Context) error {
 		Interface("http_state", cmd.HTTPState).
 		Interface("dev", cmd.DevFlags).
 		Interface("acl", cmd.ACLFlags).
+		Strs("modules", cmd.Modules).
 		Msg("flags")
 
 	cmd.RunCommand.SetLog(log.Log())
 
+	switch ids, err := loadModules(cmd.DesignFlag, cmd.Modules); {
+	case err != nil:
+		return err
+	default:
+		log.Log().Debug().Strs("modules", ids).Msg("modules composed")
+	}
+
 	if len(cmd.HTTPState) > 0 {
 		if err := cmd.RunCommand.RunHTTPState(cmd.HTTPState); err != nil {
 			return errors.Wrap(err, "failed to run http state")
@@ -114,11 +123,13 @@ func (cmd *RunCommand) pDigestAPIHandlers(ctx context.Context) (context.Context,
 	var params *launch.LocalParams
 	var local base.LocalNode
 	var design cdigest.YamlDigestDesign
+	var version util.Version
 
 	if err := util.LoadFromContextOK(ctx,
 		launch.LocalContextKey, &local,
 		launch.LocalParamsContextKey, &params,
 		cdigest.ContextValueDigestDesign, &design,
+		launch.VersionContextKey, &version,
 	); err != nil {
 		return nil, err
 	}
@@ -139,6 +150,7 @@ func (cmd *RunCommand) pDigestAPIHandlers(ctx context.Context) (context.Context,
 
 	router := dnt.Router()
 	router.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
+	router.Path(HandlerPathNodeModules).Methods(http.MethodGet).HandlerFunc(handleNodeModules(local, version))
 
 	handlers, err := ccmds.SetDigestAPIDefaultHandlers(cmd.RunCommand.Log(), ctx, params, cache, router, dnt.Queue())
 	if err != nil {
@@ -4,8 +4,11 @@ import launchcmd "github.com/imfact-labs/mitum2/launch/cmd"
 
 type Storage struct { //nolint:govet //...
 	Import         ImportCommand                  `cmd:"" help:"import block data files"`
+	Export         ExportCommand                  `cmd:"" help:"export blocks to block archive"`
 	Clean          launchcmd.CleanCommand         `cmd:"" help:"clean storage"`
 	ValidateBlocks ValidateBlocksCommand          `cmd:"" help:"validate blocks in storage"`
+	Replay         ReplayBlocksCommand            `cmd:"" help:"replay operations of blocks in storage"`
 	Status         launchcmd.StorageStatusCommand `cmd:"" help:"storage status"`
 	Database       launchcmd.DatabaseCommand      `cmd:"" help:""`
+	Cache          StorageCacheCommand            `cmd:"" help:"remote item cache of import"`
 }
@@ -0,0 +1,116 @@
+package cmds
+
+import (
+	"context"
+	"time"
+
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/pkg/errors"
+)
+
+type StorageCacheCommand struct { //nolint:govet //...
+	Stats StorageCacheStatsCommand `cmd:"" name:"stats" help:"show remote item cache stats"`
+	Prune StorageCachePruneCommand `cmd:"" name:"prune" help:"prune remote item cache"`
+}
+
+type baseStorageCacheCommand struct {
+	BaseCommand
+	Directory string `arg:"" name:"cache directory" help:"cache directory of import" type:"existingdir"`
+	Format    string `name:"format" help:"output format, {text, json}" default:"text"`
+}
+
+func (cmd *baseStorageCacheCommand) prepare(pctx context.Context) error {
+	switch cmd.Format {
+	case "text", "json":
+	default:
+		return errors.Errorf("unsupported format, %q", cmd.Format)
+	}
+
+	_, err := cmd.BaseCommand.prepare(pctx)
+
+	return err
+}
+
+func (cmd *baseStorageCacheCommand) printJSON(v interface{}) error {
+	b, err := util.MarshalJSONIndent(v)
+	if err != nil {
+		return err
+	}
+
+	cmd.print("%s", string(b))
+
+	return nil
+}
+
+type StorageCacheStatsCommand struct { //nolint:govet //...
+	baseStorageCacheCommand
+}
+
+func (cmd *StorageCacheStatsCommand) Run(pctx context.Context) error {
+	if err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	stat, err := remoteItemCacheStats(cmd.Directory)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format == "json" {
+		return cmd.printJSON(stat)
+	}
+
+	cmd.print("directory: %s", stat.Directory)
+	cmd.print("files: %d", stat.Files)
+	cmd.print("size: %s (%d)", humanBytes(uint64(stat.Size)), stat.Size)
+	cmd.print("temp files: %d", stat.TempFiles)
+
+	if stat.Files > 0 {
+		cmd.print("oldest: %s", stat.Oldest.Format(time.RFC3339))
+		cmd.print("newest: %s", stat.Newest.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+type StorageCachePruneCommand struct { //nolint:govet //...
+	baseStorageCacheCommand
+	//revive:disable:line-length-limit
+	MaxSize   ByteSizeFlag  `name:"max-size" help:"remove least recently used files until the size is under max size, like 10GiB" placeholder:"size"`
+	OlderThan time.Duration `name:"older-than" help:"remove files not used for the duration" placeholder:"duration"`
+	//revive:enable:line-length-limit
+}
+
+func (cmd *StorageCachePruneCommand) Run(pctx context.Context) error {
+	if err := cmd.prepare(pctx); err != nil {
+		return err
+	}
+
+	switch {
+	case cmd.OlderThan < 0:
+		return errors.Errorf("negative older-than, %v", cmd.OlderThan)
+	case cmd.MaxSize.Size() < 1 && cmd.OlderThan < 1:
+		return errors.Errorf("empty max-size and older-than")
+	}
+
+	pruned, err := pruneRemoteItemCache(cmd.Directory, cmd.MaxSize.Size(), cmd.OlderThan)
+	if err != nil {
+		return err
+	}
+
+	cmd.Log.Debug().
+		Str("directory", cmd.Directory).
+		Interface("max_size", cmd.MaxSize).
+		Dur("older_than", cmd.OlderThan).
+		Interface("pruned", pruned).
+		Msg("cache pruned")
+
+	if cmd.Format == "json" {
+		return cmd.printJSON(pruned)
+	}
+
+	cmd.print("pruned files: %d", pruned.Files)
+	cmd.print("pruned size: %s (%d)", humanBytes(uint64(pruned.Size)), pruned.Size)
+
+	return nil
+}
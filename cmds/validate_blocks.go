@@ -7,7 +7,6 @@ import (
 	"github.com/imfact-labs/imfact-model/runtime/steps"
 	"github.com/imfact-labs/mitum2/base"
 	"github.com/imfact-labs/mitum2/isaac"
-	isaacblock "github.com/imfact-labs/mitum2/isaac/block"
 	"github.com/imfact-labs/mitum2/launch"
 	"github.com/imfact-labs/mitum2/util"
 	"github.com/imfact-labs/mitum2/util/encoder"
@@ -22,12 +21,21 @@ var PNameValidateBlocks = ps.Name("validate-blocks")
 type ValidateBlocksCommand struct { //nolint:govet //...
 	launch.DesignFlag
 	launch.PrivatekeyFlags
-	HeightRange     launch.RangeFlag `name:"range" help:"<from>-<to>" default:""`
+	ProgressFlags
+	ValidateWorkersFlags
+	HeightRange     launch.RangeFlag    `name:"range" help:"<from>-<to>" default:""`
+	Report          string              `name:"report" help:"write validation report to file" placeholder:"file"`
+	ReportFormat    string              `name:"report-format" help:"report format, {json, yaml}; default by file extension" default:""`
+	FailFast        bool                `name:"fail-fast" help:"stop at the first failed block"`
+	Repair          bool                `name:"repair" help:"repair the failed blocks from remote node"`
+	FromRemote      launch.ConnInfoFlag `name:"from-remote" help:"remote node conn info to repair from" placeholder:"ConnInfo"`
 	log             *zerolog.Logger
 	launch.DevFlags `embed:"" prefix:"dev."`
 	fromHeight      base.Height
 	toHeight        base.Height
 	prevblockmap    base.BlockMap
+	progress        *blockProgress
+	report          *validateBlocksReport
 }
 
 func (cmd *ValidateBlocksCommand) Run(pctx context.Context) error {
@@ -36,6 +44,26 @@ func (cmd *ValidateBlocksCommand) Run(pctx context.Context) error {
 		return err
 	}
 
+	if err := cmd.ProgressFlags.IsValid(); err != nil {
+		return err
+	}
+
+	if err := cmd.ValidateWorkersFlags.IsValid(); err != nil {
+		return err
+	}
+
+	if cmd.Repair {
+		if err := cmd.FromRemote.ConnInfo().IsValid(nil); err != nil {
+			return errors.WithMessage(err, "repair needs valid --from-remote")
+		}
+	}
+
+	switch cmd.ReportFormat {
+	case "", "json", "yaml":
+	default:
+		return errors.Errorf("unsupported report format, %q", cmd.ReportFormat)
+	}
+
 	cmd.fromHeight, cmd.toHeight = base.NilHeight, base.NilHeight
 
 	if h := cmd.HeightRange.From(); h != nil {
@@ -64,6 +92,13 @@ func (cmd *ValidateBlocksCommand) Run(pctx context.Context) error {
 		Interface("dev", cmd.DevFlags).
 		Interface("from_height", cmd.fromHeight).
 		Interface("to_height", cmd.toHeight).
+		Interface("progress", cmd.ProgressFlags).
+		Interface("workers", cmd.ValidateWorkersFlags).
+		Str("report", cmd.Report).
+		Str("report_format", cmd.ReportFormat).
+		Bool("fail_fast", cmd.FailFast).
+		Bool("repair", cmd.Repair).
+		Stringer("from_remote", cmd.FromRemote).
 		Msg("flags")
 
 	cmd.log = log.Log()
@@ -178,22 +213,70 @@ func (cmd *ValidateBlocksCommand) pValidateBlocks(pctx context.Context) (context
 
 	cmd.log.Debug().Interface("height", last).Msg("last height found in source")
 
-	if err := isaacblock.IsValidBlocksFromStorage(
-		readers.Item, cmd.fromHeight, last, isaacparams.NetworkID(), db,
-		cmd.whenBlockDone,
-	); err != nil {
-		return pctx, e.Wrap(err)
+	cmd.progress = newBlockProgress(cmd.ProgressFlags, "validate-blocks", cmd.fromHeight, last)
+	cmd.progress.start(pctx)
+
+	defer cmd.progress.stop()
+
+	itemf := cmd.progress.itemFunc(readers.Item)
+
+	cmd.report = newValidateBlocksReport(cmd.fromHeight, last, cmd.FailFast)
+
+	err := validateBlocksBatch(
+		pctx,
+		cmd.fromHeight,
+		last,
+		cmd.ValidateWorkersFlags.workers(),
+		0,
+		cmd.ValidateWorkersFlags.batchSize(),
+		nil,
+		func(_ context.Context, height base.Height) error {
+			m, itemType, err := isValidBlockFromStorage(itemf, height, isaacparams.NetworkID(), db)
+
+			return cmd.whenBlockDone(height, itemType, m, err)
+		},
+		nil,
+	)
+
+	if cmd.Repair && cmd.report.Failed > 0 {
+		if rerr := cmd.repairBlocks(pctx, readers.Root(), cmd.report.failedHeights()); rerr != nil {
+			cmd.log.Error().Err(rerr).Msg("failed to repair blocks")
+		}
+	}
+
+	cmd.report.finish(err)
+
+	if len(cmd.Report) > 0 {
+		if werr := cmd.report.write(cmd.Report, cmd.ReportFormat); werr != nil {
+			cmd.log.Error().Err(werr).Str("report", cmd.Report).Msg("failed to write report")
+		}
 	}
 
-	return pctx, nil
+	switch {
+	case err != nil:
+		return pctx, e.Wrap(err)
+	case cmd.report.Failed > cmd.report.Repaired:
+		return pctx, e.Errorf("%d blocks failed to validate; repaired=%d", cmd.report.Failed, cmd.report.Repaired)
+	default:
+		return pctx, nil
+	}
 }
 
-func (cmd *ValidateBlocksCommand) whenBlockDone(m base.BlockMap, err error) error {
-	l := cmd.log.With().Interface("blockmap", m).Logger()
+func (cmd *ValidateBlocksCommand) whenBlockDone(
+	height base.Height, itemType base.BlockItemType, m base.BlockMap, err error,
+) error {
+	l := cmd.log.With().Interface("height", height).Interface("blockmap", m).Logger()
+
+	cmd.progress.blockDone(height)
+	cmd.report.add(height, itemType, err)
 
 	switch {
 	case err != nil:
-		l.Error().Err(err).Msg("failed to validate block")
+		l.Error().Err(err).Interface("item_type", itemType).Msg("failed to validate block")
+
+		if cmd.FailFast {
+			return err
+		}
 	default:
 		l.Debug().Msg("block validated")
 	}
@@ -0,0 +1,315 @@
+package cmds
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	isaacnetwork "github.com/imfact-labs/mitum2/isaac/network"
+	"github.com/imfact-labs/mitum2/launch"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/pkg/errors"
+)
+
+// repairBlocks downloads the item files of the failed heights from the remote
+// node and replaces the local ones if the downloaded block is valid.
+func (cmd *ValidateBlocksCommand) repairBlocks(pctx context.Context, root string, heights []base.Height) error {
+	e := util.StringError("repair blocks")
+
+	var encs *encoder.Encoders
+	var local base.LocalNode
+	var isaacparams *isaac.Params
+
+	if err := util.LoadFromContextOK(pctx,
+		launch.EncodersContextKey, &encs,
+		launch.LocalContextKey, &local,
+		launch.ISAACParamsContextKey, &isaacparams,
+	); err != nil {
+		return e.Wrap(err)
+	}
+
+	connectionPool, err := launch.NewConnectionPool(
+		1<<9, //nolint:gomnd //...
+		isaacparams.NetworkID(),
+		nil,
+	)
+	if err != nil {
+		return e.Wrap(err)
+	}
+
+	client := isaacnetwork.NewBaseClient(
+		encs, encs.JSON(),
+		connectionPool.Dial,
+		connectionPool.CloseAll,
+	)
+
+	defer func() {
+		_ = client.Close()
+	}()
+
+	for i := range heights {
+		err := cmd.repairBlock(pctx, client, local.Privatekey(), root, heights[i])
+
+		cmd.report.addRepair(heights[i], err)
+
+		l := cmd.log.With().Interface("height", heights[i]).Logger()
+
+		switch {
+		case err != nil:
+			l.Error().Err(err).Msg("failed to repair block")
+		default:
+			l.Info().Msg("block repaired")
+		}
+	}
+
+	return nil
+}
+
+func (cmd *ValidateBlocksCommand) repairBlock(
+	pctx context.Context,
+	client *isaacnetwork.BaseClient,
+	priv base.Privatekey,
+	root string,
+	height base.Height,
+) error {
+	var encs *encoder.Encoders
+	var isaacparams *isaac.Params
+	var db isaac.Database
+	var newReaders func(context.Context, string, *isaac.BlockItemReadersArgs) (*isaac.BlockItemReaders, error)
+
+	if err := util.LoadFromContextOK(pctx,
+		launch.EncodersContextKey, &encs,
+		launch.ISAACParamsContextKey, &isaacparams,
+		launch.CenterDatabaseContextKey, &db,
+		launch.NewBlockItemReadersFuncContextKey, &newReaders,
+	); err != nil {
+		return err
+	}
+
+	// NOTE the temp directory is in the same filesystem with root, so the
+	// downloaded files can be moved by rename.
+	temp, err := os.MkdirTemp(filepath.Dir(root), "repair-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = os.RemoveAll(temp)
+	}()
+
+	if err := cmd.downloadRepairBlock(
+		pctx, client, priv, temp, height, isaacparams.NetworkID(), encs.JSON()); err != nil {
+		return err
+	}
+
+	tempReaders, err := newReaders(pctx, temp, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tempReaders.Close()
+
+	if _, itemType, err := isValidBlockFromStorage(
+		tempReaders.Item, height, isaacparams.NetworkID(), db); err != nil {
+		return errors.WithMessagef(err, "downloaded block also invalid; item=%q", itemType)
+	}
+
+	return replaceBlockFiles(root, temp, height)
+}
+
+func (cmd *ValidateBlocksCommand) downloadRepairBlock(
+	pctx context.Context,
+	client *isaacnetwork.BaseClient,
+	priv base.Privatekey,
+	temp string,
+	height base.Height,
+	networkID base.NetworkID,
+	enc encoder.Encoder,
+) error {
+	timeout := isaac.DefaultTimeoutRequest * 2 //nolint:gomnd //...
+
+	var bfiles base.BlockItemFiles
+
+	if err := func() error {
+		ctx, cancel := context.WithTimeout(pctx, timeout)
+		defer cancel()
+
+		fname := isaac.BlockItemFilesPath(temp, height)
+
+		switch found, err := client.BlockItemFiles(
+			ctx,
+			cmd.FromRemote.ConnInfo(),
+			height,
+			priv,
+			networkID,
+			func(r io.Reader) error {
+				return saveRepairFile(r, fname)
+			},
+		); {
+		case err != nil:
+			return err
+		case !found:
+			return util.ErrNotFound.Errorf("block item files")
+		}
+
+		f, err := os.Open(filepath.Clean(fname))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		defer func() {
+			_ = f.Close()
+		}()
+
+		return encoder.DecodeReader(enc, f, &bfiles)
+	}(); err != nil {
+		return err
+	}
+
+	for t, bf := range bfiles.Items() {
+		if !isaac.IsInLocalBlockItemFile(bf.URI()) {
+			// NOTE remote item file is kept in the block item files.
+			continue
+		}
+
+		if err := func() error {
+			ctx, cancel := context.WithTimeout(pctx, timeout)
+			defer cancel()
+
+			switch found, err := client.BlockItem(
+				ctx,
+				cmd.FromRemote.ConnInfo(),
+				height,
+				t,
+				func(r io.Reader, _ url.URL, _ string) error {
+					if r == nil {
+						return util.ErrNotFound.Errorf("block item file, %q", t)
+					}
+
+					return saveRepairFile(r,
+						filepath.Join(temp, isaac.BlockHeightDirectory(height), bf.URI().Path))
+				},
+			); {
+			case err != nil:
+				return err
+			case !found:
+				return util.ErrNotFound.Errorf("block item file, %q", t)
+			default:
+				return nil
+			}
+		}(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceBlockFiles replaces the height directory and block item files of
+// root with the ones of temp. The previous files are moved into the backup
+// directory first and restored when the replacement fails; the backup is
+// removed only after the files are replaced or restored.
+func replaceBlockFiles(root, temp string, height base.Height) error {
+	backup, err := os.MkdirTemp(filepath.Dir(root), "repair-backup-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	paths := []string{
+		isaac.BlockHeightDirectory(height),
+		filepath.Join(filepath.Dir(isaac.BlockHeightDirectory(height)), base.BlockItemFilesName(height)),
+	}
+
+	var backedup, replaced []string
+
+	restore := func(err error) error {
+		if rerr := restoreBlockFiles(root, backup, backedup, replaced); rerr != nil {
+			return errors.Errorf("%v; failed to restore block files, backup kept in %q: %v", err, backup, rerr)
+		}
+
+		_ = os.RemoveAll(backup)
+
+		return err
+	}
+
+	for i := range paths {
+		target := filepath.Join(root, paths[i])
+
+		switch _, err := os.Stat(target); {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return restore(errors.WithStack(err))
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(backup, paths[i])), 0o700); err != nil {
+			return restore(errors.WithMessage(err, "backup block files"))
+		}
+
+		if err := os.Rename(target, filepath.Join(backup, paths[i])); err != nil {
+			return restore(errors.WithMessage(err, "backup block files"))
+		}
+
+		backedup = append(backedup, paths[i])
+	}
+
+	for i := range paths {
+		target := filepath.Join(root, paths[i])
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return restore(errors.WithMessage(err, "replace block files"))
+		}
+
+		if err := os.Rename(filepath.Join(temp, paths[i]), target); err != nil {
+			return restore(errors.WithMessage(err, "replace block files"))
+		}
+
+		replaced = append(replaced, paths[i])
+	}
+
+	_ = os.RemoveAll(backup)
+
+	return nil
+}
+
+// restoreBlockFiles removes the replaced files and moves back the backed up
+// files.
+func restoreBlockFiles(root, backup string, backedup, replaced []string) error {
+	for i := range replaced {
+		if err := os.RemoveAll(filepath.Join(root, replaced[i])); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for i := range backedup {
+		if err := os.Rename(filepath.Join(backup, backedup[i]), filepath.Join(root, backedup[i])); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func saveRepairFile(r io.Reader, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	f, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	_, err = io.Copy(f, r)
+
+	return errors.WithStack(err)
+}
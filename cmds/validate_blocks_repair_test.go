@@ -0,0 +1,97 @@
+package cmds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+)
+
+func TestReplaceBlockFiles(t *testing.T) {
+	height := base.Height(3)
+	heightDir := isaac.BlockHeightDirectory(height)
+	itemFiles := filepath.Join(filepath.Dir(heightDir), base.BlockItemFilesName(height))
+
+	write := func(dir, p, body string) {
+		f := filepath.Join(dir, p)
+
+		if err := os.MkdirAll(filepath.Dir(f), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(f, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(dir, p string) string {
+		b, err := os.ReadFile(filepath.Join(dir, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+
+	cases := []struct {
+		name     string
+		temp     []string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "replaced",
+			temp:     []string{filepath.Join(heightDir, "map.json"), itemFiles},
+			expected: "new",
+		},
+		{
+			name:     "restored",
+			temp:     []string{filepath.Join(heightDir, "map.json")},
+			expected: "old",
+			err:      true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			root := filepath.Join(dir, "data")
+			temp := filepath.Join(dir, "temp")
+
+			write(root, filepath.Join(heightDir, "map.json"), "old")
+			write(root, itemFiles, "old")
+
+			for i := range c.temp {
+				write(temp, c.temp[i], "new")
+			}
+
+			err := replaceBlockFiles(root, temp, height)
+
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			for _, p := range []string{filepath.Join(heightDir, "map.json"), itemFiles} {
+				if s := read(root, p); s != c.expected {
+					t.Errorf("%q: expected %q, got %q", p, c.expected, s)
+				}
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(entries) != 2 {
+				t.Errorf("backup not removed, %d", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package cmds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/imfact-labs/mitum2/util/localtime"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	blockFailureClassNotFound = "not-found"
+	blockFailureClassInvalid  = "invalid"
+	blockFailureClassMismatch = "mismatch"
+	blockFailureClassCanceled = "canceled"
+	blockFailureClassError    = "error"
+)
+
+// errBlockMismatch is the difference of block from database.
+var errBlockMismatch = util.NewIDError("mismatch")
+
+type validateBlocksReport struct {
+	StartedAt  time.Time              `json:"started_at" yaml:"started_at"`
+	FinishedAt time.Time              `json:"finished_at" yaml:"finished_at"`
+	Error      string                 `json:"error,omitempty" yaml:"error,omitempty"`
+	Failures   []validateBlockFailure `json:"failures" yaml:"failures"`
+	Repairs    []validateBlockRepair  `json:"repairs,omitempty" yaml:"repairs,omitempty"`
+	From       base.Height            `json:"from" yaml:"from"`
+	To         base.Height            `json:"to" yaml:"to"`
+	Validated  int64                  `json:"validated" yaml:"validated"`
+	Failed     int64                  `json:"failed" yaml:"failed"`
+	Repaired   int64                  `json:"repaired" yaml:"repaired"`
+	FailFast   bool                   `json:"fail_fast" yaml:"fail_fast"`
+	l          sync.Mutex
+}
+
+type validateBlockFailure struct {
+	ItemType string      `json:"item_type,omitempty" yaml:"item_type,omitempty"`
+	Class    string      `json:"class" yaml:"class"`
+	Error    string      `json:"error" yaml:"error"`
+	Height   base.Height `json:"height" yaml:"height"`
+}
+
+type validateBlockRepair struct {
+	Error    string      `json:"error,omitempty" yaml:"error,omitempty"`
+	Height   base.Height `json:"height" yaml:"height"`
+	Repaired bool        `json:"repaired" yaml:"repaired"`
+}
+
+func newValidateBlocksReport(from, to base.Height, failfast bool) *validateBlocksReport { //revive:disable-line:flag-parameter
+	return &validateBlocksReport{
+		StartedAt: localtime.Now().UTC(),
+		From:      from,
+		To:        to,
+		FailFast:  failfast,
+	}
+}
+
+func (r *validateBlocksReport) add(height base.Height, itemType base.BlockItemType, err error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if err == nil {
+		r.Validated++
+
+		return
+	}
+
+	r.Failed++
+	r.Failures = append(r.Failures, validateBlockFailure{
+		Height:   height,
+		ItemType: itemType.String(),
+		Class:    blockFailureClass(err),
+		Error:    err.Error(),
+	})
+}
+
+func (r *validateBlocksReport) failedHeights() []base.Height {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	heights := make([]base.Height, len(r.Failures))
+
+	for i := range r.Failures {
+		heights[i] = r.Failures[i].Height
+	}
+
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] < heights[j]
+	})
+
+	return heights
+}
+
+func (r *validateBlocksReport) addRepair(height base.Height, err error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	i := validateBlockRepair{Height: height, Repaired: err == nil}
+
+	switch {
+	case err != nil:
+		i.Error = err.Error()
+	default:
+		r.Repaired++
+	}
+
+	r.Repairs = append(r.Repairs, i)
+}
+
+func (r *validateBlocksReport) finish(err error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.FinishedAt = localtime.Now().UTC()
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	sort.Slice(r.Failures, func(i, j int) bool {
+		return r.Failures[i].Height < r.Failures[j].Height
+	})
+}
+
+// write writes report to file; the format is decided by format or by the file
+// extension, `.yml` and `.yaml` for yaml and the others for json.
+func (r *validateBlocksReport) write(f, format string) error {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if len(format) < 1 {
+		switch strings.ToLower(filepath.Ext(f)) {
+		case ".yml", ".yaml":
+			format = "yaml"
+		default:
+			format = "json"
+		}
+	}
+
+	var b []byte
+
+	switch format {
+	case "yaml":
+		i, err := yaml.Marshal(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		b = i
+	default:
+		i, err := util.MarshalJSONIndent(r)
+		if err != nil {
+			return err
+		}
+
+		b = i
+	}
+
+	return errors.WithStack(os.WriteFile(filepath.Clean(f), b, 0o600))
+}
+
+func blockFailureClass(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return blockFailureClassCanceled
+	case errors.Is(err, errBlockMismatch):
+		return blockFailureClassMismatch
+	case errors.Is(err, util.ErrNotFound):
+		return blockFailureClassNotFound
+	case errors.Is(err, util.ErrInvalid):
+		return blockFailureClassInvalid
+	default:
+		return blockFailureClassError
+	}
+}
@@ -0,0 +1,37 @@
+package cmds
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/pkg/errors"
+)
+
+func TestBlockFailureClass(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		class string
+	}{
+		{name: "canceled", err: errors.WithMessage(context.Canceled, "a"), class: blockFailureClassCanceled},
+		{name: "not found", err: util.ErrNotFound.Errorf("blockmap"), class: blockFailureClassNotFound},
+		{name: "invalid", err: util.ErrInvalid.Errorf("blockmap"), class: blockFailureClassInvalid},
+		{name: "mismatch", err: errBlockMismatch.Errorf("state"), class: blockFailureClassMismatch},
+		{
+			name: "mismatch of invalid",
+			err: util.StringError("validate").Wrap(
+				errBlockMismatch.WithMessage(util.ErrInvalid.Errorf("hash does not match"), "blockmap")),
+			class: blockFailureClassMismatch,
+		},
+		{name: "message only", err: errors.Errorf("hash does not match"), class: blockFailureClassError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if i := blockFailureClass(c.err); i != c.class {
+				t.Errorf("expected %q, got %q", c.class, i)
+			}
+		})
+	}
+}
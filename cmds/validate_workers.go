@@ -0,0 +1,390 @@
+package cmds
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/imfact-labs/mitum2/isaac"
+	isaacblock "github.com/imfact-labs/mitum2/isaac/block"
+	"github.com/imfact-labs/mitum2/util"
+	"github.com/pkg/errors"
+)
+
+var (
+	defaultValidateBatchSize int64 = 333 //nolint:gomnd //...
+	maxAdaptiveWorkers       int64 = 64  //nolint:gomnd //...
+	// validateBatchWindow is the number of batches validated at once.
+	validateBatchWindow int64 = 2 //nolint:gomnd //...
+)
+
+type ValidateWorkersFlags struct {
+	//revive:disable:line-length-limit
+	Workers   int64 `name:"workers" help:"number of workers to validate blocks; 0 decides by cpu count" default:"0" placeholder:"count"`
+	BatchSize int64 `name:"batch-size" help:"number of blocks validated in one batch; 0 decides by workers" default:"0" placeholder:"count"`
+	//revive:enable:line-length-limit
+}
+
+func (f ValidateWorkersFlags) IsValid() error {
+	switch {
+	case f.Workers < 0:
+		return errors.Errorf("negative workers, %d", f.Workers)
+	case f.BatchSize < 0:
+		return errors.Errorf("negative batch size, %d", f.BatchSize)
+	default:
+		return nil
+	}
+}
+
+// workers returns the number of workers; without workers flag, the number of
+// workers is adaptive by cpu count. Validation reads files a lot, so it is
+// higher than cpu count.
+func (f ValidateWorkersFlags) workers() int64 {
+	if f.Workers > 0 {
+		return f.Workers
+	}
+
+	n := int64(runtime.NumCPU()) * 2 //nolint:gomnd //...
+
+	switch {
+	case n < 2: //nolint:gomnd //...
+		return 2 //nolint:gomnd //...
+	case n > maxAdaptiveWorkers:
+		return maxAdaptiveWorkers
+	default:
+		return n
+	}
+}
+
+func (f ValidateWorkersFlags) batchSize() int64 {
+	switch {
+	case f.BatchSize > 0:
+		return f.BatchSize
+	case f.workers() > defaultValidateBatchSize:
+		return f.workers()
+	default:
+		return defaultValidateBatchSize
+	}
+}
+
+// validateBlocksBatch runs f for each height from fromHeight to lastHeight. If
+// isRemote is not nil, the heights of remote item files are passed to the
+// separated remote workers, so slow remote items does not block the local ones;
+// the local and remote workers run independently within validateBatchWindow
+// batches. batchDone is called with the last height of batch in order, after
+// every height of the batch and the previous batches is done.
+func validateBlocksBatch(
+	ctx context.Context,
+	fromHeight, lastHeight base.Height,
+	workers, remoteWorkers, batchSize int64,
+	isRemote func(base.Height) (bool, error),
+	f func(context.Context, base.Height) error,
+	batchDone func(base.Height) error,
+) error {
+	if lastHeight < fromHeight {
+		return nil
+	}
+
+	batches := newValidateBatches(fromHeight, lastHeight, batchSize, batchDone)
+
+	heights := make(chan base.Height)
+	remotes := make(chan base.Height, batchSize*validateBatchWindow)
+
+	done := func(ctx context.Context, height base.Height) error {
+		if err := f(ctx, height); err != nil {
+			return err
+		}
+
+		return batches.done(height)
+	}
+
+	return util.RunJobWorkerByJobs(ctx,
+		func(ctx context.Context, _ uint64) error {
+			defer close(heights)
+
+			for height := fromHeight; height <= lastHeight; height++ {
+				if err := batches.wait(ctx, height); err != nil {
+					return err
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case heights <- height:
+				}
+			}
+
+			return nil
+		},
+		func(ctx context.Context, _ uint64) error {
+			defer close(remotes)
+
+			return runHeightsWorker(ctx, workers, heights, func(ctx context.Context, height base.Height) error {
+				switch remote, err := isRemoteHeight(isRemote, height); {
+				case err != nil:
+					return err
+				case !remote:
+					return done(ctx, height)
+				}
+
+				// NOTE remotes has enough buffer for the heights in window.
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case remotes <- height:
+					return nil
+				}
+			})
+		},
+		func(ctx context.Context, _ uint64) error {
+			return runHeightsWorker(ctx, remoteWorkers, remotes, done)
+		},
+	)
+}
+
+// validateBatches tracks the done heights by batch; the heights beyond the
+// window from the first not done batch wait.
+type validateBatches struct {
+	counts    map[int64]int64
+	batchDone func(base.Height) error
+	advanced  chan struct{}
+	from      base.Height
+	last      base.Height
+	size      int64
+	next      int64
+	sync.Mutex
+}
+
+func newValidateBatches(
+	from, last base.Height, size int64, batchDone func(base.Height) error,
+) *validateBatches {
+	return &validateBatches{
+		from:      from,
+		last:      last,
+		size:      size,
+		batchDone: batchDone,
+		counts:    map[int64]int64{},
+		advanced:  make(chan struct{}),
+	}
+}
+
+func (b *validateBatches) index(height base.Height) int64 {
+	return (height - b.from).Int64() / b.size
+}
+
+// bounds returns the first and last height of batch.
+func (b *validateBatches) bounds(index int64) (base.Height, base.Height) {
+	first := b.from + base.Height(index*b.size)
+
+	last := first + base.Height(b.size) - 1
+	if last > b.last {
+		last = b.last
+	}
+
+	return first, last
+}
+
+func (b *validateBatches) wait(ctx context.Context, height base.Height) error {
+	for {
+		b.Lock()
+
+		if b.index(height) < b.next+validateBatchWindow {
+			b.Unlock()
+
+			return nil
+		}
+
+		advanced := b.advanced
+
+		b.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-advanced:
+		}
+	}
+}
+
+func (b *validateBatches) done(height base.Height) error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.counts[b.index(height)]++
+
+	for {
+		first, last := b.bounds(b.next)
+
+		if b.counts[b.next] < (last-first).Int64()+1 {
+			return nil
+		}
+
+		delete(b.counts, b.next)
+
+		if b.batchDone != nil {
+			if err := b.batchDone(last); err != nil {
+				return err
+			}
+		}
+
+		b.next++
+
+		close(b.advanced)
+		b.advanced = make(chan struct{})
+
+		if last >= b.last {
+			return nil
+		}
+	}
+}
+
+func isRemoteHeight(isRemote func(base.Height) (bool, error), height base.Height) (bool, error) {
+	if isRemote == nil {
+		return false, nil
+	}
+
+	return isRemote(height)
+}
+
+func runHeightsWorker(
+	ctx context.Context,
+	workers int64,
+	heights <-chan base.Height,
+	f func(context.Context, base.Height) error,
+) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make([]util.ContextWorkerCallback, workers)
+
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context, _ uint64) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case height, ok := <-heights:
+					if !ok {
+						return nil
+					}
+
+					if err := f(ctx, height); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return util.RunJobWorkerByJobs(ctx, jobs...)
+}
+
+// hasRemoteItemFile checks whether the block item files of height has remote
+// item file. The item files are cached by readers, so the validation of height
+// reuses them.
+func hasRemoteItemFile(readers *isaac.BlockItemReaders, height base.Height) (bool, error) {
+	switch bfiles, found, err := readers.ItemFiles(height); {
+	case err != nil:
+		return false, err
+	case !found:
+		return false, nil
+	default:
+		for _, i := range bfiles.Items() {
+			if !isaac.IsInLocalBlockItemFile(i.URI()) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// isValidBlockFromStorage validates block of height by
+// isaacblock.IsValidBlockFromLocalFS with database like
+// isaacblock.IsValidBlocksFromStorage; the differences from database are
+// wrapped by errBlockMismatch. If failed, it returns the type of the failed
+// block item if known.
+func isValidBlockFromStorage(
+	itemf isaac.BlockItemReadersItemFunc,
+	height base.Height,
+	networkID base.NetworkID,
+	db isaac.Database,
+) (base.BlockMap, base.BlockItemType, error) {
+	var failedl sync.Mutex
+	var failed base.BlockItemType
+
+	var mapdb base.BlockMap
+
+	switch i, found, err := db.BlockMap(height); {
+	case err != nil:
+		return nil, failed, err
+	case !found:
+		return nil, failed, util.ErrNotFound.Errorf("blockmap not found in database; %d", height)
+	default:
+		mapdb = i
+	}
+
+	err := isaacblock.IsValidBlockFromLocalFS(
+		func(
+			height base.Height, t base.BlockItemType, f isaac.BlockItemReaderCallbackFunc,
+		) (base.BlockItemFile, bool, error) {
+			itemfile, found, err := itemf(height, t, f)
+			if err != nil {
+				failedl.Lock()
+				defer failedl.Unlock()
+
+				if len(failed) < 1 {
+					failed = t
+				}
+			}
+
+			return itemfile, found, err
+		},
+		height,
+		networkID,
+		func(m base.BlockMap) error {
+			if err := base.IsEqualBlockMap(mapdb, m); err != nil {
+				return errBlockMismatch.WithMessage(err, "blockmap")
+			}
+
+			return nil
+		},
+		func(op base.Operation) error {
+			switch found, err := db.ExistsKnownOperation(op.Hash()); {
+			case err != nil:
+				return err
+			case !found:
+				return util.ErrNotFound.Errorf("operation not found in database; %q", op.Hash())
+			default:
+				return nil
+			}
+		},
+		func(st base.State) error {
+			switch rst, found, err := db.State(st.Key()); {
+			case err != nil:
+				return err
+			case !found:
+				return util.ErrNotFound.Errorf("state not found in database; %q", st.Key())
+			case !base.IsEqualState(st, rst):
+				return errBlockMismatch.Errorf("state of %q", st.Key())
+			}
+
+			ops := st.Operations()
+
+			for i := range ops {
+				switch found, err := db.ExistsInStateOperation(ops[i]); {
+				case err != nil:
+					return err
+				case !found:
+					return util.ErrNotFound.Errorf("operation of state not found in database; %q", ops[i])
+				}
+			}
+
+			return nil
+		},
+	)
+
+	return mapdb, failed, err
+}
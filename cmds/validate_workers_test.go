@@ -0,0 +1,120 @@
+package cmds
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/imfact-labs/mitum2/base"
+	"github.com/pkg/errors"
+)
+
+func TestValidateBlocksBatch(t *testing.T) {
+	cases := []struct {
+		name      string
+		from      base.Height
+		last      base.Height
+		batchSize int64
+		isRemote  func(base.Height) (bool, error)
+		failAt    base.Height
+	}{
+		{name: "local", from: 0, last: 99, batchSize: 7},
+		{name: "one batch", from: 3, last: 5, batchSize: 10},
+		{
+			name: "remote", from: 1, last: 120, batchSize: 9,
+			isRemote: func(h base.Height) (bool, error) { return h%3 == 0, nil },
+		},
+		{
+			name: "all remote", from: 0, last: 50, batchSize: 4,
+			isRemote: func(base.Height) (bool, error) { return true, nil },
+		},
+		{name: "failed", from: 0, last: 99, batchSize: 7, failAt: 33},
+		{
+			name: "remote failed", from: 0, last: 99, batchSize: 7, failAt: 42,
+			isRemote: func(h base.Height) (bool, error) { return h%2 == 0, nil },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var l sync.Mutex
+			done := map[base.Height]int{}
+			var batches []base.Height
+
+			err := validateBlocksBatch(context.Background(), c.from, c.last, 3, 2, c.batchSize, c.isRemote,
+				func(_ context.Context, height base.Height) error {
+					if c.failAt > 0 && height == c.failAt {
+						return errors.Errorf("failed")
+					}
+
+					l.Lock()
+					defer l.Unlock()
+
+					done[height]++
+
+					return nil
+				},
+				func(height base.Height) error {
+					l.Lock()
+					defer l.Unlock()
+
+					for h := c.from; h <= height; h++ {
+						if done[h] != 1 {
+							t.Errorf("batch %d done before height %d", height, h)
+						}
+					}
+
+					batches = append(batches, height)
+
+					return nil
+				},
+			)
+
+			if c.failAt > 0 {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				for i := range batches {
+					if batches[i] >= c.failAt {
+						t.Errorf("batch %d done after failed height %d", batches[i], c.failAt)
+					}
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			for h := c.from; h <= c.last; h++ {
+				if done[h] != 1 {
+					t.Errorf("height %d done %d times", h, done[h])
+				}
+			}
+
+			var expected []base.Height
+
+			for h := c.from + base.Height(c.batchSize) - 1; ; h += base.Height(c.batchSize) {
+				if h >= c.last {
+					expected = append(expected, c.last)
+
+					break
+				}
+
+				expected = append(expected, h)
+			}
+
+			if len(batches) != len(expected) {
+				t.Fatalf("batches: expected %v, got %v", expected, batches)
+			}
+
+			for i := range expected {
+				if batches[i] != expected[i] {
+					t.Errorf("batch %d: expected %d, got %d", i, expected[i], batches[i])
+				}
+			}
+		})
+	}
+}
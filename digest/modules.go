@@ -0,0 +1,67 @@
+package digest
+
+import (
+	ccmodule "github.com/imfact-labs/currency-model/app/module"
+	cdigest "github.com/imfact-labs/currency-model/digest"
+	daodigest "github.com/imfact-labs/dao-model/digest"
+	daomodule "github.com/imfact-labs/dao-model/module"
+	ndigest "github.com/imfact-labs/nft-model/digest"
+	nmodule "github.com/imfact-labs/nft-model/module"
+	pmdigest "github.com/imfact-labs/payment-model/digest"
+	pmmodule "github.com/imfact-labs/payment-model/module"
+	sdigest "github.com/imfact-labs/storage-model/digest"
+	smodule "github.com/imfact-labs/storage-model/module"
+	tsdigest "github.com/imfact-labs/timestamp-model/digest"
+	tsmodule "github.com/imfact-labs/timestamp-model/module"
+	tdigest "github.com/imfact-labs/token-model/digest"
+	tkmodule "github.com/imfact-labs/token-model/module"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ModuleDigest is what a module entry of the module registry contributes to
+// the digester.
+type ModuleDigest struct {
+	Indexes      map[string] /* collection */ []mongo.IndexModel
+	PrepareFuncs []cdigest.BlockSessionPrepareFunc
+}
+
+// moduleDigests maps the module id to its digest contribution.
+var moduleDigests = map[string]ModuleDigest{
+	ccmodule.Module{}.ID(): {
+		// NOTE currency indexes are created by cdigest.ProcessDigesterDatabase.
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{
+			cdigest.PrepareCurrencies, cdigest.PrepareAccounts, cdigest.PrepareDIDRegistry,
+		},
+	},
+	daomodule.Module{}.ID(): {
+		Indexes:      daodigest.DefaultIndexes,
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{daodigest.PrepareDAO},
+	},
+	nmodule.Module{}.ID(): {
+		Indexes:      ndigest.DefaultIndexes,
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{ndigest.PrepareNFTs},
+	},
+	pmmodule.Module{}.ID(): {
+		Indexes:      pmdigest.DefaultIndexes,
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{pmdigest.PreparePayment},
+	},
+	smodule.Module{}.ID(): {
+		Indexes:      sdigest.DefaultIndexes,
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{sdigest.PrepareStorage},
+	},
+	tsmodule.Module{}.ID(): {
+		Indexes:      tsdigest.DefaultIndexes,
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{tsdigest.PrepareTimeStamps},
+	},
+	tkmodule.Module{}.ID(): {
+		Indexes:      tdigest.DefaultIndexes,
+		PrepareFuncs: []cdigest.BlockSessionPrepareFunc{tdigest.PrepareToken},
+	},
+}
+
+// LoadModuleDigest returns the digest contribution of the module entry.
+func LoadModuleDigest(id string) (ModuleDigest, bool) {
+	d, found := moduleDigests[id]
+
+	return d, found
+}
@@ -4,16 +4,12 @@ import (
 	"context"
 
 	cdigest "github.com/imfact-labs/currency-model/digest"
-	daodigest "github.com/imfact-labs/dao-model/digest"
+	"github.com/imfact-labs/imfact-model/runtime/spec"
 	"github.com/imfact-labs/mitum2/isaac"
 	"github.com/imfact-labs/mitum2/launch"
 	"github.com/imfact-labs/mitum2/util"
 	"github.com/imfact-labs/mitum2/util/logging"
-	ndigest "github.com/imfact-labs/nft-model/digest"
-	pmdigest "github.com/imfact-labs/payment-model/digest"
-	sdigest "github.com/imfact-labs/storage-model/digest"
-	tsdigest "github.com/imfact-labs/timestamp-model/digest"
-	tdigest "github.com/imfact-labs/token-model/digest"
+	"github.com/pkg/errors"
 )
 
 func ProcessDigester(ctx context.Context) (context.Context, error) {
@@ -42,20 +38,22 @@ func ProcessDigester(ctx context.Context) (context.Context, error) {
 		return ctx, nil
 	}
 
-	if err := st.CreateIndex(ndigest.DefaultIndexes); err != nil {
-		return ctx, err
-	}
-	if err := st.CreateIndex(tsdigest.DefaultIndexes); err != nil {
-		return ctx, err
-	}
-	if err := st.CreateIndex(tdigest.DefaultIndexes); err != nil {
-		return ctx, err
-	}
-	if err := st.CreateIndex(daodigest.DefaultIndexes); err != nil {
-		return ctx, err
-	}
-	if err := st.CreateIndex(pmdigest.DefaultIndexes); err != nil {
-		return ctx, err
+	entries := spec.MustBuildModuleRegistry().Entries()
+	digests := make([]ModuleDigest, len(entries))
+
+	for i := range entries {
+		d, found := LoadModuleDigest(entries[i].ID)
+		if !found {
+			return ctx, errors.Errorf("digest of module not found, %q", entries[i].ID)
+		}
+
+		if len(d.Indexes) > 0 {
+			if err := st.CreateIndex(d.Indexes); err != nil {
+				return ctx, errors.WithMessagef(err, "create digest indexes of module, %q", entries[i].ID)
+			}
+		}
+
+		digests[i] = d
 	}
 
 	var design launch.NodeDesign
@@ -87,10 +85,9 @@ func ProcessDigester(ctx context.Context) (context.Context, error) {
 
 	di := cdigest.NewDigester(st, root, sourceReaders, fromRemotes, design.NetworkID, vs.String(), nil)
 	_ = di.SetLogging(log)
-	di.PrepareFunc = []cdigest.BlockSessionPrepareFunc{
-		cdigest.PrepareCurrencies, cdigest.PrepareAccounts, cdigest.PrepareDIDRegistry,
-		daodigest.PrepareDAO, ndigest.PrepareNFTs, pmdigest.PreparePayment,
-		sdigest.PrepareStorage, tsdigest.PrepareTimeStamps, tdigest.PrepareToken,
+
+	for i := range digests {
+		di.PrepareFunc = append(di.PrepareFunc, digests[i].PrepareFuncs...)
 	}
 
 	return context.WithValue(ctx, cdigest.ContextValueDigester, di), nil
@@ -33,19 +33,23 @@ var (
 //revive:disable:nested-structs
 var CLI struct { //nolint:govet //...
 	launch.BaseFlags
-	Init      ccmds.INITCommand `cmd:"" help:"init node"`
-	Run       cmds.RunCommand   `cmd:"" help:"run node"`
-	Storage   cmds.Storage      `cmd:""`
+	Init      ccmds.INITCommand   `cmd:"" help:"init node"`
+	Run       cmds.RunCommand     `cmd:"" help:"run node"`
+	Storage   cmds.Storage        `cmd:""`
+	Genesis   cmds.GenesisCommand `cmd:"" help:"genesis design"`
+	Devnet    cmds.DevnetCommand  `cmd:"" help:"local devnet"`
 	Operation struct {
-		Currency    ccmds.CurrencyCommand   `cmd:"" help:"currency operation"`
-		Suffrage    ccmds.SuffrageCommand   `cmd:"" help:"suffrage operation"`
-		NFT         ncmds.NFTCommand        `cmd:"" help:"nft operation"`
-		Dao         dcmds.DAOCommand        `cmd:"" help:"dao operation"`
-		Timestamp   tscmds.TimestampCommand `cmd:"" help:"timestamp operation"`
-		Token       tcmds.TokenCommand      `cmd:"" help:"token operation"`
-		StorageData scmds.StorageCommand    `cmd:"" help:"data storage operation"`
-		DID         ccmds.DIDCommand        `cmd:"" help:"did operation"`
-		Payment     pmcmds.PaymentCommand   `cmd:"" help:"payment operation"`
+		Currency    ccmds.CurrencyCommand         `cmd:"" help:"currency operation"`
+		Suffrage    ccmds.SuffrageCommand         `cmd:"" help:"suffrage operation"`
+		NFT         ncmds.NFTCommand              `cmd:"" help:"nft operation"`
+		Dao         dcmds.DAOCommand              `cmd:"" help:"dao operation"`
+		Timestamp   tscmds.TimestampCommand       `cmd:"" help:"timestamp operation"`
+		Token       tcmds.TokenCommand            `cmd:"" help:"token operation"`
+		StorageData scmds.StorageCommand          `cmd:"" help:"data storage operation"`
+		DID         ccmds.DIDCommand              `cmd:"" help:"did operation"`
+		Payment     pmcmds.PaymentCommand         `cmd:"" help:"payment operation"`
+		Validate    cmds.OperationValidateCommand `cmd:"" help:"validate operation"`
+		Sign        cmds.OperationSignCommand     `cmd:"" help:"sign operation offline"`
 	} `cmd:"" help:"create operation"`
 	Network struct {
 		Client cmds.NetworkClientCommand `cmd:"" help:"network client"`
@@ -57,6 +61,7 @@ var CLI struct { //nolint:govet //...
 		Sign    ccmds.KeySignCommand    `cmd:"" help:"sign"`
 	} `cmd:"" help:"key"`
 	Handover launchcmd.HandoverCommands `cmd:""`
+	Modules  cmds.ModulesCommand        `cmd:"" help:"composed model modules"`
 	Version  struct{}                   `cmd:"" help:"version"`
 }
 
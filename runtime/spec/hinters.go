@@ -1,16 +1,62 @@
 package spec
 
-import "github.com/imfact-labs/mitum2/util/encoder"
+import (
+	"sync/atomic"
+
+	"github.com/imfact-labs/currency-model/app/modulekit"
+	"github.com/imfact-labs/mitum2/util/encoder"
+	"github.com/imfact-labs/mitum2/util/hint"
+)
 
 var Hinters []encoder.DecodeDetail
 var SupportedProposalOperationFactHinters []encoder.DecodeDetail
 
+// supportedProposalOperationFactHints indexes the hints of
+// SupportedProposalOperationFactHinters by type; one type can have multiple
+// versions.
+var supportedProposalOperationFactHints atomic.Pointer[map[hint.Type][]hint.Hint]
+
 func init() {
-	registry := MustBuildModuleRegistry()
+	loadFromRegistry(MustBuildModuleRegistry())
+}
+
+// IsSupportedProposalOperationFactHint checks whether the fact hint is
+// compatible with one of the supported proposal operation fact hints.
+func IsSupportedProposalOperationFactHint(ht hint.Hint) bool {
+	m := supportedProposalOperationFactHints.Load()
+	if m == nil {
+		return false
+	}
+
+	hs := (*m)[ht.Type()]
+
+	for i := range hs {
+		if ht.IsCompatible(hs[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func loadFromRegistry(registry *modulekit.Registry) {
 	entries := registry.Entries()
 
+	var hinters, facts []encoder.DecodeDetail
+
 	for i := range entries {
-		Hinters = append(Hinters, entries[i].Hinters...)
-		SupportedProposalOperationFactHinters = append(SupportedProposalOperationFactHinters, entries[i].SupportedFacts...)
+		hinters = append(hinters, entries[i].Hinters...)
+		facts = append(facts, entries[i].SupportedFacts...)
 	}
+
+	m := make(map[hint.Type][]hint.Hint, len(facts))
+
+	for i := range facts {
+		ht := facts[i].Hint
+		m[ht.Type()] = append(m[ht.Type()], ht)
+	}
+
+	Hinters = hinters
+	SupportedProposalOperationFactHinters = facts
+	supportedProposalOperationFactHints.Store(&m)
 }
@@ -1,6 +1,7 @@
 package spec
 
 import (
+	"strings"
 	"sync"
 
 	ccmodule "github.com/imfact-labs/currency-model/app/module"
@@ -11,8 +12,13 @@ import (
 	smodule "github.com/imfact-labs/storage-model/module"
 	tsmodule "github.com/imfact-labs/timestamp-model/module"
 	tkmodule "github.com/imfact-labs/token-model/module"
+	"github.com/pkg/errors"
 )
 
+// RequiredModuleID is the module which is always composed, regardless of the
+// module selection.
+const RequiredModuleID = ccmodule.ID
+
 var composedModules = []modulekit.ModelModule{
 	ccmodule.Module{},
 	nmodule.Module{},
@@ -24,30 +30,76 @@ var composedModules = []modulekit.ModelModule{
 }
 
 var (
-	moduleRegistryOnce sync.Once
+	moduleRegistryLock sync.Mutex
 	moduleRegistry     *modulekit.Registry
 	moduleRegistryErr  error
+	selectedModules    []modulekit.ModelModule
 )
 
+// AvailableModuleIDs returns the ids of all the modules built in this binary,
+// in composing order.
+func AvailableModuleIDs() []string {
+	ids := make([]string, len(composedModules))
+
+	for i := range composedModules {
+		ids[i] = composedModules[i].ID()
+	}
+
+	return ids
+}
+
+// SetModules selects the modules by id and rebuilds the module registry.
+// Empty ids selects all the available modules. The required module is added if
+// missing.
+func SetModules(ids []string) error {
+	modules, err := selectModules(ids)
+	if err != nil {
+		return err
+	}
+
+	registry, err := buildModuleRegistry(modules)
+	if err != nil {
+		return err
+	}
+
+	moduleRegistryLock.Lock()
+	defer moduleRegistryLock.Unlock()
+
+	selectedModules = modules
+	moduleRegistry, moduleRegistryErr = registry, nil
+
+	loadFromRegistry(registry)
+
+	return nil
+}
+
 func LoadModuleRegistry() (*modulekit.Registry, error) {
-	moduleRegistryOnce.Do(func() {
-		moduleRegistry, moduleRegistryErr = buildModuleRegistry()
-	})
+	moduleRegistryLock.Lock()
+	defer moduleRegistryLock.Unlock()
+
+	if moduleRegistry == nil && moduleRegistryErr == nil {
+		modules := selectedModules
+		if modules == nil {
+			modules = composedModules
+		}
+
+		moduleRegistry, moduleRegistryErr = buildModuleRegistry(modules)
+	}
 
 	return moduleRegistry, moduleRegistryErr
 }
 
-func buildModuleRegistry() (*modulekit.Registry, error) {
+func buildModuleRegistry(modules []modulekit.ModelModule) (*modulekit.Registry, error) {
 	registry := modulekit.NewRegistry()
 
-	for i := range composedModules {
-		if err := registry.Register(composedModules[i]); err != nil {
+	for i := range modules {
+		if err := registry.Register(modules[i]); err != nil {
 			return nil, err
 		}
 	}
 
-	for i := range composedModules {
-		if err := registry.ValidateModuleContract(composedModules[i].ID()); err != nil {
+	for i := range modules {
+		if err := registry.ValidateModuleContract(modules[i].ID()); err != nil {
 			return nil, err
 		}
 	}
@@ -63,3 +115,45 @@ func MustBuildModuleRegistry() *modulekit.Registry {
 
 	return registry
 }
+
+func selectModules(ids []string) ([]modulekit.ModelModule, error) {
+	if len(ids) < 1 {
+		return composedModules, nil
+	}
+
+	selected := map[string]bool{RequiredModuleID: true}
+
+	for i := range ids {
+		id := strings.TrimSpace(ids[i])
+
+		switch {
+		case len(id) < 1:
+			return nil, errors.Errorf("empty module id")
+		case !isAvailableModule(id):
+			return nil, errors.Errorf("unknown module, %q; available=%q", id, AvailableModuleIDs())
+		default:
+			selected[id] = true
+		}
+	}
+
+	// NOTE keep the composing order, not the selection order.
+	modules := make([]modulekit.ModelModule, 0, len(selected))
+
+	for i := range composedModules {
+		if selected[composedModules[i].ID()] {
+			modules = append(modules, composedModules[i])
+		}
+	}
+
+	return modules, nil
+}
+
+func isAvailableModule(id string) bool {
+	for i := range composedModules {
+		if composedModules[i].ID() == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -47,16 +47,5 @@ func LoadHinters(encs *encoder.Encoders) error {
 }
 
 func IsSupportedProposalOperationFactHintFunc() func(hint.Hint) bool {
-	return func(ht hint.Hint) bool {
-		for i := range spec.SupportedProposalOperationFactHinters {
-			s := spec.SupportedProposalOperationFactHinters[i].Hint
-			if ht.Type() != s.Type() {
-				continue
-			}
-
-			return ht.IsCompatible(s)
-		}
-
-		return false
-	}
+	return spec.IsSupportedProposalOperationFactHint
 }